@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"maciejadamski/pkg/engine"
 
 	"github.com/joho/godotenv"
 
 	"maciejadamski/templates/pages"
+	"maciejadamski/templates/pages/authors"
 	"maciejadamski/templates/pages/blog"
 )
 
@@ -16,11 +21,29 @@ func main() {
 	_ = godotenv.Load()
 	setupLogger()
 	registry := engine.ComponentRegistry{
-		Index:     pages.Home,
-		BlogIndex: blog.Index,
-		BlogPost:  blog.PostPage,
+		Index:      pages.Home,
+		BlogIndex:  blog.Index,
+		BlogPost:   blog.PostPage,
+		AuthorPage: authors.Profile,
 	}
-	if err := engine.Build(registry, engine.DefaultOptions()); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	opts := engine.DefaultOptions()
+	opts.Environment = os.Getenv("SITE_ENV")
+	if err := engine.Build(ctx, registry, opts); err != nil {
+		var canceled *engine.CanceledError
+		if errors.As(err, &canceled) {
+			slog.Info("build canceled", "stage", canceled.Stage)
+			os.Exit(130)
+		}
+		var broken *engine.BrokenLinksError
+		if errors.As(err, &broken) {
+			for _, link := range broken.Links {
+				slog.Error("broken link", "page", link.Page, "url", link.URL, "reason", link.Reason)
+			}
+			slog.Error("build failed", "broken_links", len(broken.Links))
+			os.Exit(1)
+		}
 		slog.Error("build failed", "error", err)
 		os.Exit(1)
 	}
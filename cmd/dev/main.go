@@ -1,19 +1,62 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"maciejadamski/pkg/engine"
 	"maciejadamski/pkg/server"
 
 	"github.com/joho/godotenv"
+
+	"maciejadamski/templates/pages"
+	"maciejadamski/templates/pages/authors"
+	"maciejadamski/templates/pages/blog"
 )
 
 func main() {
 	_ = godotenv.Load()
 	setupLogger()
-	port := os.Getenv("PORT")
-	if err := server.Run(port, "dist"); err != nil {
+	registry := engine.ComponentRegistry{
+		Index:      pages.Home,
+		BlogIndex:  blog.Index,
+		BlogPost:   blog.PostPage,
+		AuthorPage: authors.Profile,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// The site is built into memory and served from there; dist/ is left
+	// to cmd/build. A fresh memory output has nothing to reuse, so the
+	// incremental cache is disabled.
+	out := engine.NewMemoryOutput()
+	opts := engine.DefaultOptions()
+	opts.Output = out
+	opts.OutputDir = ""
+	opts.CacheDir = ""
+	opts.Environment = os.Getenv("SITE_ENV")
+	if err := engine.Build(ctx, registry, opts); err != nil {
+		var canceled *engine.CanceledError
+		if errors.As(err, &canceled) {
+			slog.Info("build canceled", "stage", canceled.Stage)
+			os.Exit(130)
+		}
+		var broken *engine.BrokenLinksError
+		if errors.As(err, &broken) {
+			for _, link := range broken.Links {
+				slog.Error("broken link", "page", link.Page, "url", link.URL, "reason", link.Reason)
+			}
+		}
+		slog.Error("build failed", "error", err)
+		os.Exit(1)
+	}
+	stop()
+
+	if err := server.RunFS(os.Getenv("PORT"), out); err != nil {
 		slog.Error("server_failed", "error", err)
 		os.Exit(1)
 	}
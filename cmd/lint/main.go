@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/lint"
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+	setupLogger()
+
+	configDir := flag.String("config", "config", "config directory (site.yaml, lint.yaml)")
+	contentDir := flag.String("content", "content", "content directory to lint")
+	format := flag.String("format", "text", "output format: text or json")
+	flag.Parse()
+
+	issues, err := run(*configDir, *contentDir)
+	if err != nil {
+		slog.Error("lint failed", "error", err)
+		os.Exit(2)
+	}
+
+	if err := report(issues, *format); err != nil {
+		slog.Error("lint failed", "error", err)
+		os.Exit(2)
+	}
+
+	for _, issue := range issues {
+		if issue.Severity == audit.SeverityError {
+			os.Exit(1)
+		}
+	}
+}
+
+// run loads the lint and site config and lints the content directory.
+func run(configDir, contentDir string) ([]lint.Issue, error) {
+	cfg, err := lint.LoadConfig(filepath.Join(configDir, "lint.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	site, err := website.LoadSiteConfig(filepath.Join(configDir, "site.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("loading site config: %w", err)
+	}
+
+	var langs []string
+	for _, l := range website.Languages(site) {
+		langs = append(langs, l.Code)
+	}
+
+	return lint.Dir(contentDir, cfg, markdown.Options{
+		Location:        website.GetLocation(site),
+		DefaultLanguage: website.GetLanguage(site),
+		Languages:       langs,
+	})
+}
+
+// report writes issues to stdout as "file:line: rule: message" lines or a JSON array.
+func report(issues []lint.Issue, format string) error {
+	switch format {
+	case "text":
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		return nil
+	case "json":
+		if issues == nil {
+			issues = []lint.Issue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func setupLogger() {
+	level := slog.LevelInfo
+	if env := os.Getenv("LOG_LEVEL"); env != "" {
+		_ = level.UnmarshalText([]byte(env))
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+	})))
+}
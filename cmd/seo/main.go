@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/seo"
+	"maciejadamski/pkg/website"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+	setupLogger()
+
+	configDir := flag.String("config", "config", "config directory (site.yaml)")
+	distDir := flag.String("dist", "dist", "generated site to audit")
+	format := flag.String("format", "table", "output format: table or json")
+	flag.Parse()
+
+	site, err := website.LoadSiteConfig(filepath.Join(*configDir, "site.yaml"))
+	if err != nil {
+		slog.Error("seo audit failed", "error", fmt.Errorf("loading site config: %w", err))
+		os.Exit(2)
+	}
+
+	report, err := seo.Audit(os.DirFS(*distDir), site)
+	if err != nil {
+		slog.Error("seo audit failed", "error", err)
+		os.Exit(2)
+	}
+
+	if err := write(report, *format); err != nil {
+		slog.Error("seo audit failed", "error", err)
+		os.Exit(2)
+	}
+
+	if report.Count(audit.SeverityError) > 0 {
+		os.Exit(1)
+	}
+}
+
+// write prints the report to stdout as a table of issues or as JSON.
+func write(report seo.Report, format string) error {
+	switch format {
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PAGE\tSEVERITY\tRULE\tMESSAGE")
+		for _, page := range report.Pages {
+			for _, issue := range page.Issues {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", page.Path, issue.Severity, issue.Rule, issue.Message)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d pages, %d errors, %d warnings\n",
+			len(report.Pages), report.Count(audit.SeverityError), report.Count(audit.SeverityWarning))
+		return nil
+	case "json":
+		if report.Pages == nil {
+			report.Pages = []seo.Page{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func setupLogger() {
+	level := slog.LevelInfo
+	if env := os.Getenv("LOG_LEVEL"); env != "" {
+		_ = level.UnmarshalText([]byte(env))
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+	})))
+}
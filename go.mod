@@ -3,11 +3,15 @@ module maciejadamski
 go 1.25.5
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/a-h/templ v0.3.960
 	github.com/joho/godotenv v1.5.1
 	github.com/yuin/goldmark v1.7.13
-	github.com/yuin/goldmark-meta v1.1.0
+	golang.org/x/net v0.57.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require gopkg.in/yaml.v2 v2.3.0 // indirect
+require (
+	golang.org/x/image v0.40.0
+	golang.org/x/text v0.40.0 // indirect
+)
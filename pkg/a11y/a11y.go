@@ -0,0 +1,274 @@
+// Package a11y audits generated HTML pages and theme colors for common
+// accessibility problems.
+package a11y
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"maciejadamski/pkg/audit"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Issue is a single accessibility problem found in a page or the theme.
+type Issue struct {
+	// Page is the output file, e.g. "blog/index.html", or "theme" for
+	// color contrast issues.
+	Page     string         `json:"page"`
+	Rule     string         `json:"rule"`
+	Severity audit.Severity `json:"severity"`
+	// Element identifies the offending element, e.g. `<img src="/a.png">`.
+	Element string `json:"element,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the issue as "page: rule: message element".
+func (i Issue) String() string {
+	if i.Element == "" {
+		return fmt.Sprintf("%s: %s: %s", i.Page, i.Rule, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s %s", i.Page, i.Rule, i.Message, i.Element)
+}
+
+// Audit checks every HTML page in fsys and returns the issues of the rules
+// enabled in cfg, sorted by page.
+func Audit(fsys fs.FS, cfg Config) ([]Issue, error) {
+	var issues []Issue
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		page, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		pageIssues, err := AuditPage(name, page, cfg)
+		if err != nil {
+			return fmt.Errorf("auditing %s: %w", name, err)
+		}
+		issues = append(issues, pageIssues...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Page < issues[j].Page
+	})
+	return issues, nil
+}
+
+// AuditPage checks a single HTML page. Documents without an <html> tag,
+// such as verification files, are not checked for a lang attribute.
+func AuditPage(name string, page []byte, cfg Config) ([]Issue, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+	a := auditor{page: name, cfg: cfg, labels: make(map[string]bool), ids: make(map[string]int)}
+	a.collect(doc)
+
+	if bytes.Contains(bytes.ToLower(page), []byte("<html")) {
+		a.checkLang(doc)
+	}
+	a.walk(doc)
+	for _, id := range sortedDuplicates(a.ids) {
+		a.report(RuleDuplicateID, "", fmt.Sprintf("id %q is used %d times", id, a.ids[id]))
+	}
+	return a.issues, nil
+}
+
+// auditor accumulates the issues of one page.
+type auditor struct {
+	page   string
+	cfg    Config
+	issues []Issue
+	// labels holds the ids referenced by <label for>.
+	labels map[string]bool
+	// ids counts the elements per id.
+	ids map[string]int
+	// heading is the level of the previous heading, 0 before the first.
+	heading int
+}
+
+func (a *auditor) report(rule, element, message string) {
+	severity, ok := a.cfg.Rules[rule]
+	if !ok || severity == audit.SeverityOff {
+		return
+	}
+	a.issues = append(a.issues, Issue{Page: a.page, Rule: rule, Severity: severity, Element: element, Message: message})
+}
+
+// collect records label targets and id counts before the elements are checked.
+func (a *auditor) collect(n *html.Node) {
+	if n.Type == html.ElementNode {
+		if id := attr(n, "id"); id != "" {
+			a.ids[id]++
+		}
+		if n.Data == "label" {
+			if target := attr(n, "for"); target != "" {
+				a.labels[target] = true
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		a.collect(c)
+	}
+}
+
+func (a *auditor) checkLang(doc *html.Node) {
+	for c := doc.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "html" && strings.TrimSpace(attr(c, "lang")) == "" {
+			a.report(RuleHTMLLang, "<html>", "page has no lang attribute")
+		}
+	}
+}
+
+func (a *auditor) walk(n *html.Node) {
+	if n.Type == html.ElementNode {
+		a.check(n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		a.walk(c)
+	}
+}
+
+// check applies the element rules to n.
+func (a *auditor) check(n *html.Node) {
+	switch n.Data {
+	case "img", "area":
+		if !hasAttr(n, "alt") && attr(n, "role") != "presentation" && attr(n, "aria-hidden") != "true" {
+			a.report(RuleImageAlt, describe(n), "image has no alt attribute")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(n.Data[1] - '0')
+		if a.heading > 0 && level > a.heading+1 {
+			a.report(RuleHeadingSkip, describe(n), fmt.Sprintf("heading level skips from h%d to h%d", a.heading, level))
+		}
+		a.heading = level
+	case "a":
+		if hasAttr(n, "href") && accessibleName(n) == "" {
+			a.report(RuleEmptyLink, describe(n), "link has no text or accessible name")
+		}
+	case "button":
+		if accessibleName(n) == "" {
+			a.report(RuleEmptyButton, describe(n), "button has no text or accessible name")
+		}
+	case "input":
+		switch strings.ToLower(attr(n, "type")) {
+		case "hidden", "submit", "reset", "button":
+			return
+		case "image":
+			if strings.TrimSpace(attr(n, "alt")) == "" && !hasLabel(n) {
+				a.report(RuleImageAlt, describe(n), "image button has no alt attribute")
+			}
+			return
+		}
+		fallthrough
+	case "select", "textarea":
+		if !hasLabel(n) && !(attr(n, "id") != "" && a.labels[attr(n, "id")]) && !insideLabel(n) {
+			a.report(RuleFormLabel, describe(n), "form control has no label")
+		}
+	}
+}
+
+// accessibleName approximates the accessible name of a link or button:
+// its ARIA label, its visible text and image alternatives, or its title.
+func accessibleName(n *html.Node) string {
+	if name := strings.TrimSpace(attr(n, "aria-label")); name != "" {
+		return name
+	}
+	if strings.TrimSpace(attr(n, "aria-labelledby")) != "" {
+		return attr(n, "aria-labelledby")
+	}
+	var b strings.Builder
+	textContent(&b, n)
+	if name := strings.TrimSpace(b.String()); name != "" {
+		return name
+	}
+	return strings.TrimSpace(attr(n, "title"))
+}
+
+// textContent appends the text of n's visible descendants, using the alt
+// text of images and the labels of labelled descendants.
+func textContent(b *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			b.WriteString(c.Data)
+		case html.ElementNode:
+			if attr(c, "aria-hidden") == "true" || hasAttr(c, "hidden") {
+				continue
+			}
+			if label := attr(c, "aria-label"); label != "" {
+				b.WriteString(label)
+				continue
+			}
+			if c.Data == "img" {
+				b.WriteString(attr(c, "alt"))
+				continue
+			}
+			textContent(b, c)
+		}
+	}
+}
+
+// hasLabel reports whether a form control is labelled through ARIA or title.
+func hasLabel(n *html.Node) bool {
+	return strings.TrimSpace(attr(n, "aria-label")) != "" ||
+		strings.TrimSpace(attr(n, "aria-labelledby")) != "" ||
+		strings.TrimSpace(attr(n, "title")) != ""
+}
+
+// insideLabel reports whether n is wrapped in a <label>.
+func insideLabel(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// describe renders the start tag of n with its identifying attribute.
+func describe(n *html.Node) string {
+	for _, key := range []string{"id", "src", "href", "name", "class"} {
+		if v := attr(n, key); v != "" {
+			return fmt.Sprintf("<%s %s=%q>", n.Data, key, v)
+		}
+	}
+	return "<" + n.Data + ">"
+}
+
+func sortedDuplicates(ids map[string]int) []string {
+	var dups []string
+	for id, count := range ids {
+		if count > 1 {
+			dups = append(dups, id)
+		}
+	}
+	sort.Strings(dups)
+	return dups
+}
@@ -0,0 +1,173 @@
+package a11y
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/website"
+)
+
+func rules(issues []Issue) []string {
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Rule)
+	}
+	return got
+}
+
+func TestAuditPage(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []string
+	}{
+		{
+			name: "clean page",
+			page: `<!DOCTYPE html><html lang="en"><body><h1>Title</h1><h2>Section</h2>
+<img src="/a.png" alt=""><a href="/"><span class="sr-only">Home</span><svg aria-hidden="true"></svg></a>
+<a href="/x"><img src="/logo.png" alt="Logo"></a><button aria-label="Open menu"><svg></svg></button>
+<label for="q">Search</label><input id="q"><label>Name <input name="name"></label>
+<input type="hidden" name="token"><input type="submit" value="Send"></body></html>`,
+		},
+		{
+			name: "missing lang",
+			page: `<html><body><p>Hi</p></body></html>`,
+			want: []string{RuleHTMLLang},
+		},
+		{
+			name: "fragment without html tag",
+			page: `google-site-verification: google123.html`,
+		},
+		{
+			name: "missing alt",
+			page: `<html lang="en"><img src="/a.png"><img src="/b.png" role="presentation"></html>`,
+			want: []string{RuleImageAlt},
+		},
+		{
+			name: "skipped heading",
+			page: `<html lang="en"><h1>A</h1><h3>B</h3><h2>C</h2><h3>D</h3></html>`,
+			want: []string{RuleHeadingSkip},
+		},
+		{
+			name: "empty link and button",
+			page: `<html lang="en"><a href="/"><svg aria-hidden="true"></svg></a><a href="/x"> </a><a name="anchor"></a>
+<button><span aria-hidden="true">×</span></button><button title="Close"></button></html>`,
+			want: []string{RuleEmptyLink, RuleEmptyLink, RuleEmptyButton},
+		},
+		{
+			name: "unlabelled controls",
+			page: `<html lang="en"><input type="email"><select></select><textarea></textarea><input placeholder="x" aria-label="Query"></html>`,
+			want: []string{RuleFormLabel, RuleFormLabel, RuleFormLabel},
+		},
+		{
+			name: "duplicate ids",
+			page: `<html lang="en"><div id="a"></div><div id="a"></div><div id="b"></div></html>`,
+			want: []string{RuleDuplicateID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := AuditPage("index.html", []byte(tt.page), DefaultConfig())
+			if err != nil {
+				t.Fatalf("AuditPage() error = %v", err)
+			}
+			if got := rules(issues); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("AuditPage() rules = %v, want %v\n%v", got, tt.want, issues)
+			}
+		})
+	}
+}
+
+func TestAuditPage_Element(t *testing.T) {
+	issues, err := AuditPage("blog/index.html", []byte(`<html lang="en"><img src="/a.png"></html>`), DefaultConfig())
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("AuditPage() = %v, %v; want one issue", issues, err)
+	}
+	want := `blog/index.html: image-alt: image has no alt attribute <img src="/a.png">`
+	if got := issues[0].String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":      {Data: []byte(`<html lang="en"><img src="/a.png"></html>`)},
+		"blog/index.html": {Data: []byte(`<html><h1>A</h1></html>`)},
+		"static/a.css":    {Data: []byte(`body{}`)},
+	}
+	cfg, err := NewConfig(map[string]string{RuleHTMLLang: "warning", RuleImageAlt: "off"})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	issues, err := Audit(fsys, cfg)
+	if err != nil {
+		t.Fatalf("Audit() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Page != "blog/index.html" || issues[0].Severity != audit.SeverityWarning {
+		t.Errorf("Audit() = %v, want one html-lang warning in blog/index.html", issues)
+	}
+}
+
+func TestNewConfig_Invalid(t *testing.T) {
+	for _, overrides := range []map[string]string{
+		{"no-such-rule": "error"},
+		{RuleImageAlt: "fatal"},
+	} {
+		if _, err := NewConfig(overrides); err == nil {
+			t.Errorf("NewConfig(%v) error = nil, want error", overrides)
+		}
+	}
+}
+
+func TestContrast(t *testing.T) {
+	theme := website.DefaultTheme()
+	theme.ColorPageBackground = "#ffffff"
+	theme.ColorSurface = "#fff"
+	theme.ColorTextHeading = "#000"
+	theme.ColorTextBody = "#777777"
+	theme.ColorTextMuted = "#595959"
+	theme.ColorTextBold = "#000000"
+	theme.ColorTextItalic = "#333333"
+	theme.ColorTextCode = "#52008d"
+	theme.ColorLink = "var(--blue)"
+	theme.ColorTextPre = "#e8eaed"
+	theme.ColorCodeBackground = "#212226"
+
+	issues := Contrast(theme, DefaultConfig())
+	if len(issues) != 2 {
+		t.Fatalf("Contrast() = %v, want body text on both backgrounds", issues)
+	}
+	for _, issue := range issues {
+		if !strings.HasPrefix(issue.Message, "color_text_body (#777777)") || issue.Severity != audit.SeverityWarning {
+			t.Errorf("issue = %v, want color_text_body warning", issue)
+		}
+	}
+
+	cfg, _ := NewConfig(map[string]string{RuleColorContrast: "off"})
+	if issues := Contrast(theme, cfg); len(issues) != 0 {
+		t.Errorf("Contrast() with rule off = %v, want none", issues)
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+		ok   bool
+	}{
+		{"#000000", "#ffffff", 21, true},
+		{"#fff", "#fff", 1, true},
+		{"#767676", "#ffffff", 4.54, true},
+		{"red", "#ffffff", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ContrastRatio(tt.a, tt.b)
+		if ok != tt.ok || (ok && (got < tt.want-0.01 || got > tt.want+0.01)) {
+			t.Errorf("ContrastRatio(%q, %q) = %.2f, %v; want %.2f, %v", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package a11y
+
+import (
+	"fmt"
+	"maciejadamski/pkg/audit"
+)
+
+// Rule names, as used in output and in the config.
+const (
+	RuleImageAlt      = "image-alt"
+	RuleDuplicateID   = "duplicate-id"
+	RuleHeadingSkip   = "heading-skip"
+	RuleEmptyLink     = "empty-link"
+	RuleEmptyButton   = "empty-button"
+	RuleFormLabel     = "form-label"
+	RuleHTMLLang      = "html-lang"
+	RuleColorContrast = "color-contrast"
+)
+
+// Config holds the severity of every rule, keyed by rule name.
+type Config struct {
+	Rules map[string]audit.Severity
+}
+
+// DefaultConfig returns the built-in rule severities.
+func DefaultConfig() Config {
+	return Config{Rules: map[string]audit.Severity{
+		RuleImageAlt:      audit.SeverityError,
+		RuleDuplicateID:   audit.SeverityError,
+		RuleHeadingSkip:   audit.SeverityWarning,
+		RuleEmptyLink:     audit.SeverityError,
+		RuleEmptyButton:   audit.SeverityError,
+		RuleFormLabel:     audit.SeverityError,
+		RuleHTMLLang:      audit.SeverityError,
+		RuleColorContrast: audit.SeverityWarning,
+	}}
+}
+
+// NewConfig applies severity overrides by rule name on top of DefaultConfig.
+func NewConfig(overrides map[string]string) (Config, error) {
+	cfg := DefaultConfig()
+	for rule, value := range overrides {
+		if _, ok := cfg.Rules[rule]; !ok {
+			return cfg, fmt.Errorf("unknown accessibility rule %q", rule)
+		}
+		severity, err := audit.ParseSeverity(value, cfg.Rules[rule])
+		if err != nil {
+			return cfg, fmt.Errorf("accessibility rule %q: %w", rule, err)
+		}
+		cfg.Rules[rule] = severity
+	}
+	return cfg, nil
+}
@@ -0,0 +1,107 @@
+package a11y
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/website"
+)
+
+// MinContrast is the WCAG AA contrast ratio for normal text.
+const MinContrast = 4.5
+
+// colorPair is a text color token shown on a background token.
+type colorPair struct {
+	text, background           string
+	textColor, backgroundColor string
+}
+
+// themePairs returns the text and background combinations the templates use.
+func themePairs(t website.ThemeConfig) []colorPair {
+	texts := []struct{ name, color string }{
+		{"color_text_heading", t.ColorTextHeading},
+		{"color_text_body", t.ColorTextBody},
+		{"color_text_muted", t.ColorTextMuted},
+		{"color_text_bold", t.ColorTextBold},
+		{"color_text_italic", t.ColorTextItalic},
+		{"color_text_code", t.ColorTextCode},
+		{"color_link", t.ColorLink},
+	}
+	backgrounds := []struct{ name, color string }{
+		{"color_page_background", t.ColorPageBackground},
+		{"color_surface", t.ColorSurface},
+	}
+	var pairs []colorPair
+	for _, bg := range backgrounds {
+		for _, text := range texts {
+			pairs = append(pairs, colorPair{text.name, bg.name, text.color, bg.color})
+		}
+	}
+	return append(pairs, colorPair{"color_text_pre", "color_code_background", t.ColorTextPre, t.ColorCodeBackground})
+}
+
+// Contrast checks the theme's text and background token pairs against
+// MinContrast. Colors other than #rgb and #rrggbb are skipped.
+func Contrast(theme website.ThemeConfig, cfg Config) []Issue {
+	severity, ok := cfg.Rules[RuleColorContrast]
+	if !ok || severity == audit.SeverityOff {
+		return nil
+	}
+	var issues []Issue
+	for _, p := range themePairs(theme) {
+		ratio, ok := ContrastRatio(p.textColor, p.backgroundColor)
+		if !ok || ratio >= MinContrast {
+			continue
+		}
+		issues = append(issues, Issue{
+			Page:     "theme",
+			Rule:     RuleColorContrast,
+			Severity: severity,
+			Message: fmt.Sprintf("%s (%s) on %s (%s) has contrast %.2f:1, want at least %.1f:1",
+				p.text, p.textColor, p.background, p.backgroundColor, ratio, MinContrast),
+		})
+	}
+	return issues
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two hex colors.
+func ContrastRatio(a, b string) (float64, bool) {
+	la, ok := luminance(a)
+	if !ok {
+		return 0, false
+	}
+	lb, ok := luminance(b)
+	if !ok {
+		return 0, false
+	}
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05), true
+}
+
+// luminance returns the relative luminance of a #rgb or #rrggbb color.
+func luminance(hex string) (float64, bool) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	channel := func(shift uint) float64 {
+		c := float64((v>>shift)&0xff) / 255
+		if c <= 0.04045 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(16) + 0.7152*channel(8) + 0.0722*channel(0), true
+}
@@ -0,0 +1,39 @@
+// Package audit holds what the content lint, the accessibility audit and the
+// SEO audit share.
+package audit
+
+import "fmt"
+
+// Severity is the importance of an issue.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	// SeverityOff disables a rule, or failing the build when used as a
+	// threshold.
+	SeverityOff Severity = "off"
+)
+
+// AtLeast reports whether s is as severe as threshold. Nothing reaches the
+// SeverityOff threshold.
+func (s Severity) AtLeast(threshold Severity) bool {
+	switch threshold {
+	case SeverityWarning:
+		return s == SeverityWarning || s == SeverityError
+	case SeverityError:
+		return s == SeverityError
+	}
+	return false
+}
+
+// ParseSeverity validates a severity from the config. Empty means def.
+func ParseSeverity(s string, def Severity) (Severity, error) {
+	switch Severity(s) {
+	case "":
+		return def, nil
+	case SeverityError, SeverityWarning, SeverityOff:
+		return Severity(s), nil
+	}
+	return "", fmt.Errorf("invalid severity %q (want error, warning or off)", s)
+}
@@ -0,0 +1,39 @@
+package audit
+
+import "testing"
+
+func TestSeverity_AtLeast(t *testing.T) {
+	tests := []struct {
+		s, threshold Severity
+		want         bool
+	}{
+		{SeverityError, SeverityError, true},
+		{SeverityWarning, SeverityError, false},
+		{SeverityError, SeverityWarning, true},
+		{SeverityWarning, SeverityWarning, true},
+		{SeverityError, SeverityOff, false},
+	}
+	for _, tt := range tests {
+		if got := tt.s.AtLeast(tt.threshold); got != tt.want {
+			t.Errorf("%s.AtLeast(%s) = %v, want %v", tt.s, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    Severity
+		wantErr bool
+	}{
+		{in: "", want: SeverityWarning},
+		{in: "error", want: SeverityError},
+		{in: "off", want: SeverityOff},
+		{in: "fatal", wantErr: true},
+	} {
+		got, err := ParseSeverity(tt.in, SeverityWarning)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseSeverity(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
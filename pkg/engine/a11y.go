@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"maciejadamski/pkg/a11y"
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/website"
+)
+
+// AccessibilityError is returned by Build when the accessibility audit finds
+// issues at or above the configured threshold.
+type AccessibilityError struct {
+	FailOn audit.Severity
+	// Issues are the issues at or above FailOn.
+	Issues []a11y.Issue
+}
+
+func (e *AccessibilityError) Error() string {
+	return fmt.Sprintf("accessibility audit: %d issue(s) at or above %s", len(e.Issues), e.FailOn)
+}
+
+// auditAccessibility audits the HTML pages in out and the theme colors,
+// logs every issue and returns an *AccessibilityError when any reaches
+// site.Accessibility.FailOn.
+func auditAccessibility(ctx context.Context, out fs.FS, site website.SiteConfig) error {
+	cfg, err := a11y.NewConfig(site.Accessibility.Rules)
+	if err != nil {
+		return err
+	}
+	failOn, err := audit.ParseSeverity(site.Accessibility.FailOn, audit.SeverityError)
+	if err != nil {
+		return fmt.Errorf("accessibility fail_on: %w", err)
+	}
+
+	issues, err := a11y.Audit(out, cfg)
+	if err != nil {
+		return fmt.Errorf("auditing accessibility: %w", err)
+	}
+	issues = append(a11y.Contrast(site.Theme, cfg), issues...)
+
+	var failing []a11y.Issue
+	for _, issue := range issues {
+		level := slog.LevelWarn
+		if issue.Severity.AtLeast(failOn) {
+			level = slog.LevelError
+			failing = append(failing, issue)
+		}
+		slog.Log(ctx, level, "accessibility issue", "page", issue.Page, "rule", issue.Rule, "severity", issue.Severity, "element", issue.Element, "message", issue.Message)
+	}
+	slog.Info("accessibility audited", "issues", len(issues), "failing", len(failing))
+
+	if len(failing) > 0 {
+		return &AccessibilityError{FailOn: failOn, Issues: failing}
+	}
+	return nil
+}
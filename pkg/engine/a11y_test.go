@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/a11y"
+)
+
+func TestBuild_Accessibility(t *testing.T) {
+	page := fstest.MapFS{
+		"index.html": {Data: []byte(`<html lang="en"><h1>A</h1><img src="/missing-alt.png"><h3>B</h3></html>`)},
+	}
+	tests := []struct {
+		name      string
+		config    string
+		wantRules []string // failing rules, none when the build passes
+	}{
+		{name: "audit off", config: ""},
+		{name: "fails on errors", config: "accessibility:\n  audit: true\n", wantRules: []string{a11y.RuleImageAlt}},
+		{name: "fails on warnings", config: "accessibility:\n  audit: true\n  fail_on: warning\n  rules:\n    color-contrast: \"off\"\n", wantRules: []string{a11y.RuleImageAlt, a11y.RuleHeadingSkip}},
+		{name: "report only", config: "accessibility:\n  audit: true\n  fail_on: \"off\"\n"},
+		{name: "rule disabled", config: "accessibility:\n  audit: true\n  rules:\n    image-alt: \"off\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, out := memoryTestOptions()
+			opts.ConfigFS = fstest.MapFS{
+				"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\n" + tt.config)},
+			}
+			opts.PublicFS = page
+
+			err := Build(context.Background(), ComponentRegistry{}, opts)
+			if tt.wantRules == nil {
+				if err != nil {
+					t.Fatalf("Build() error = %v", err)
+				}
+				if _, err := fs.Stat(out, "index.html"); err != nil {
+					t.Errorf("output not published: %v", err)
+				}
+				return
+			}
+
+			var audit *AccessibilityError
+			if !errors.As(err, &audit) {
+				t.Fatalf("Build() error = %v, want *AccessibilityError", err)
+			}
+			var got []string
+			for _, issue := range audit.Issues {
+				got = append(got, issue.Rule)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantRules, ",") {
+				t.Errorf("failing rules = %v, want %v", got, tt.wantRules)
+			}
+			if _, err := fs.Stat(out, "index.html"); err == nil {
+				t.Error("output published despite failing audit")
+			}
+		})
+	}
+}
+
+func TestBuild_AccessibilityInvalidConfig(t *testing.T) {
+	opts, _ := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\naccessibility:\n  audit: true\n  fail_on: fatal\n")},
+	}
+	if err := Build(context.Background(), ComponentRegistry{}, opts); err == nil {
+		t.Error("Build() error = nil, want invalid fail_on error")
+	}
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+// assetComponent renders a page linking the stylesheet through website.Asset
+// and the script through a raw path.
+type assetComponent struct {
+	site website.SiteConfig
+}
+
+func (c assetComponent) Render(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, `<link href="`+website.Asset(c.site, "/static/css/site.css")+`"><script src="/static/app.js"></script>`)
+	return err
+}
+
+func assetComponents() ComponentRegistry {
+	return ComponentRegistry{
+		Index: func(site website.SiteConfig, _ website.SEO, _ []markdown.Post) templ.Component {
+			return assetComponent{site: site}
+		},
+	}
+}
+
+func TestBuild_FingerprintAssets(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\nassets:\n  fingerprint: true\n")},
+	}
+	opts.StaticFS = fstest.MapFS{
+		"css/site.css": {Data: []byte("body {}")},
+		"app.js":       {Data: []byte("console.log(1)")},
+		"logo.png":     {Data: []byte("png")},
+		"_headers":     {Data: []byte("/*\n")},
+	}
+	if err := Build(context.Background(), assetComponents(), opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	data, err := fs.ReadFile(out, "assets.json")
+	if err != nil {
+		t.Fatalf("asset manifest not written: %v", err)
+	}
+	var manifest map[string]string
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) != 2 {
+		t.Fatalf("manifest = %v, want site.css and app.js", manifest)
+	}
+
+	css, js := manifest["/static/css/site.css"], manifest["/static/app.js"]
+	if err := fstest.TestFS(out, strings.TrimPrefix(css, "/"), strings.TrimPrefix(js, "/"), "static/logo.png", "static/_headers"); err != nil {
+		t.Errorf("output fs: %v", err)
+	}
+	if _, err := fs.Stat(out, "static/css/site.css"); err == nil {
+		t.Error("original name of fingerprinted file was written")
+	}
+
+	page, err := fs.ReadFile(out, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<link href="` + css + `"><script src="` + js + `"></script>`
+	if string(page) != want {
+		t.Errorf("index.html = %q, want %q", page, want)
+	}
+}
+
+func TestBuild_FingerprintIncremental(t *testing.T) {
+	tmpDir := t.TempDir()
+	opts := BuildOptions{
+		OutputDir:  filepath.Join(tmpDir, "dist"),
+		ConfigDir:  filepath.Join(tmpDir, "config"),
+		ContentDir: filepath.Join(tmpDir, "content"),
+		StaticDir:  filepath.Join(tmpDir, "static"),
+		CacheDir:   filepath.Join(tmpDir, "tmp", ".buildcache"),
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(opts.ConfigDir, "site.yaml"), "name: Test\nurl: https://example.com\nassets:\n  fingerprint: true\n")
+	write(filepath.Join(opts.StaticDir, "css", "site.css"), "body {}")
+
+	build := func() string {
+		t.Helper()
+		if err := Build(context.Background(), assetComponents(), opts); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		page, err := os.ReadFile(filepath.Join(opts.OutputDir, "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(page)
+	}
+
+	first := build()
+	write(filepath.Join(opts.StaticDir, "css", "site.css"), "body { margin: 0 }")
+	second := build()
+
+	if first == second {
+		t.Fatalf("page not re-rendered after the stylesheet changed: %q", second)
+	}
+	matches, _ := filepath.Glob(filepath.Join(opts.OutputDir, "static", "css", "*.css"))
+	if len(matches) != 1 || !strings.Contains(second, filepath.Base(matches[0])) {
+		t.Errorf("stylesheets = %v, want only the one page %q links", matches, second)
+	}
+}
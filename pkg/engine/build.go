@@ -1,12 +1,20 @@
 package engine
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
-	"maciejadamski/pkg/generator"
 	"maciejadamski/pkg/markdown"
 	"maciejadamski/pkg/website"
 
@@ -24,6 +32,9 @@ type ComponentRegistry struct {
 
 	// BlogPost renders individual blog posts.
 	BlogPost func(website.SiteConfig, website.SEO, markdown.Post) templ.Component
+
+	// AuthorPage renders an author profile with the author's posts (optional).
+	AuthorPage func(website.SiteConfig, website.SEO, website.Author, []markdown.Post) templ.Component
 }
 
 // BuildOptions configures the build process paths.
@@ -32,6 +43,67 @@ type BuildOptions struct {
 	ConfigDir  string
 	ContentDir string
 	StaticDir  string
+	// CacheDir holds the incremental build manifest. Empty disables caching.
+	CacheDir string
+	// Plugins hook into the build in registration order.
+	Plugins []Plugin
+
+	// PublicDir holds passthrough files copied to the root of the output,
+	// such as _headers or favicon.ico. Empty disables it.
+	PublicDir string
+	// FontsDir holds local fonts: self-hosted font families and the fonts of
+	// Open Graph images. Empty disables it.
+	FontsDir string
+
+	// ConfigFS, ContentFS, StaticFS, PublicFS and FontsFS replace ConfigDir,
+	// ContentDir, StaticDir, PublicDir and FontsDir when set, e.g. with an
+	// embed.FS or fstest.MapFS.
+	ConfigFS  fs.FS
+	ContentFS fs.FS
+	StaticFS  fs.FS
+	PublicFS  fs.FS
+	FontsFS   fs.FS
+	// Output receives the build. Defaults to a DiskOutput at OutputDir.
+	Output Output
+
+	// Environment names the build target. Empty means production; other
+	// values keep crawlers out via robots.txt.
+	Environment string
+
+	// HTTPClient sends the requests of the external link check. Defaults to
+	// a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// withDefaults fills unset inputs and output from the directory paths.
+func (o BuildOptions) withDefaults() BuildOptions {
+	if o.ConfigFS == nil {
+		o.ConfigFS = os.DirFS(dirOrCurrent(o.ConfigDir))
+	}
+	if o.ContentFS == nil {
+		o.ContentFS = os.DirFS(dirOrCurrent(o.ContentDir))
+	}
+	if o.StaticFS == nil {
+		o.StaticFS = os.DirFS(dirOrCurrent(o.StaticDir))
+	}
+	if o.PublicFS == nil && o.PublicDir != "" {
+		o.PublicFS = os.DirFS(o.PublicDir)
+	}
+	if o.FontsFS == nil && o.FontsDir != "" {
+		o.FontsFS = os.DirFS(o.FontsDir)
+	}
+	if o.Output == nil {
+		o.Output = NewDiskOutput(dirOrCurrent(o.OutputDir))
+	}
+	return o
+}
+
+// dirOrCurrent maps an empty directory path to the current directory.
+func dirOrCurrent(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
 }
 
 // DefaultOptions returns standard build paths.
@@ -41,32 +113,123 @@ func DefaultOptions() BuildOptions {
 		ConfigDir:  "config",
 		ContentDir: "content",
 		StaticDir:  "static",
+		PublicDir:  "public",
+		FontsDir:   "fonts",
+		CacheDir:   filepath.Join("tmp", ".buildcache"),
 	}
 }
 
 // Build generates the static site using the provided components and options.
-func Build(components ComponentRegistry, opts BuildOptions) error {
+// Pages are written to a staging output, which replaces the previous output
+// only when the whole build succeeds.
+// If ctx is canceled, Build stops promptly and returns a *CanceledError,
+// leaving the output untouched. Components can read the page path and build
+// time from their context with website.PagePath and website.BuildTime.
+func Build(ctx context.Context, components ComponentRegistry, opts BuildOptions) error {
 	slog.Info("starting build", "output", opts.OutputDir)
+	ctx = website.WithBuildTime(ctx, time.Now())
+	opts = opts.withDefaults()
 
-	if err := os.RemoveAll(opts.OutputDir); err != nil {
-		return fmt.Errorf("cleaning output directory: %w", err)
+	cache, err := openCache(opts)
+	if err != nil {
+		return err
 	}
 
-	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+	staging, err := opts.Output.Begin(!cache.full)
+	if err != nil {
+		return err
 	}
+	defer staging.Discard() // no-op once committed
 
-	site, err := loadSiteWithTheme(opts.ConfigDir)
+	site, err := build(ctx, components, opts, cache.track(staging), cache)
 	if err != nil {
+		return asCanceled(ctx, "build", err)
+	}
+	if err := cache.prune(staging); err != nil {
 		return err
 	}
+	if err := pluginList(opts.Plugins).buildComplete(cache.track(staging)); err != nil {
+		return asCanceled(ctx, "build", err)
+	}
+	if site.Links.Check {
+		if err := CheckLinks(ctx, staging, site, opts.HTTPClient); err != nil {
+			return err
+		}
+	}
+	if site.Accessibility.Audit {
+		if err := auditAccessibility(ctx, staging, site); err != nil {
+			return err
+		}
+	}
+	if err := checkCanceled(ctx, "publish"); err != nil {
+		return err
+	}
+
+	if err := staging.Commit(); err != nil {
+		return err
+	}
+	if err := cache.save(); err != nil {
+		return err
+	}
+
+	slog.Info("build completed", "output", opts.OutputDir)
+	return nil
+}
+
+// build renders every page, static file and SEO file into out and returns
+// the site config it was built with.
+func build(ctx context.Context, components ComponentRegistry, opts BuildOptions, out OutputFS, cache *buildCache) (website.SiteConfig, error) {
+	plugins := pluginList(opts.Plugins)
+
+	site, err := loadSiteWithTheme(opts.ConfigFS)
+	if err != nil {
+		return site, err
+	}
+	site.Environment = opts.Environment
+	if err := plugins.configLoaded(&site); err != nil {
+		return site, err
+	}
+	site.FontAssets = fontAssets(site)
+	cache.minify = newMinifier(site)
+	if cache.ogImages, err = newOGImageRenderer(site, opts.FontsFS); err != nil {
+		return site, err
+	}
 
-	blogDir := filepath.Join(opts.ContentDir, "blog")
-	posts, err := markdown.ParseDir(blogDir)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("parsing blog directory: %w", err)
+	assets, err := copyStaticFiles(ctx, opts.StaticFS, out, cache, site.Assets)
+	if err != nil {
+		return site, err
+	}
+	if err := copyPublicFiles(ctx, opts.PublicFS, out, cache); err != nil {
+		return site, err
+	}
+	site.AssetURLs = assets
+	if err := cache.setAssets(assets); err != nil {
+		return site, err
+	}
+
+	langs := website.Languages(site)
+	if err := checkCanceled(ctx, "parsing"); err != nil {
+		return site, err
+	}
+	posts, err := markdown.ParseFS(ctx, opts.ContentFS, "blog", markdown.Options{
+		Location:        website.GetLocation(site),
+		DefaultLanguage: website.GetLanguage(site),
+		Languages:       languageCodes(langs),
+		RawHTML:         rawHTMLPolicy(site.RawHTML),
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return site, fmt.Errorf("parsing blog directory: %w", err)
+	}
+	if err := reportRawHTML(posts, site.RawHTML.Strict); err != nil {
+		return site, err
+	}
+	if posts, err = plugins.postsParsed(site, posts); err != nil {
+		return site, err
 	}
 	publishedPosts := filterPublished(posts)
+	if err := checkLanguages(publishedPosts, langs); err != nil {
+		return site, err
+	}
 
 	if components.Index != nil {
 		seo := website.SEO{
@@ -74,67 +237,128 @@ func Build(components ComponentRegistry, opts BuildOptions) error {
 			Description: site.Description,
 			IsHomePage:  true,
 		}
-		outputPath := filepath.Join(opts.OutputDir, "index.html")
-		slog.Debug("rendering homepage", "path", outputPath)
-		if err := generator.RenderTemplComponent(outputPath, components.Index(site, seo, publishedPosts)); err != nil {
-			return fmt.Errorf("rendering homepage: %w", err)
+		slog.Debug("rendering homepage")
+		homePosts := filterLanguage(publishedPosts, website.GetLanguage(site))
+		if err := cache.render(ctx, out, "index.html", pageInputs{SEO: seo, Posts: homePosts}, components.Index(site, seo, homePosts)); err != nil {
+			return site, fmt.Errorf("rendering homepage: %w", err)
 		}
 	}
 
-	if err := buildBlog(components, opts, site, publishedPosts); err != nil {
-		return err
+	translations := groupTranslations(publishedPosts)
+	indexAlternates := blogIndexAlternates(site, publishedPosts)
+	authorPosts := filterLanguage(publishedPosts, website.GetLanguage(site))
+	authors := authorPages(components, site, authorPosts)
+	for _, lang := range langs {
+		langSite := website.ForLanguage(site, lang)
+		langPosts := filterLanguage(publishedPosts, lang.Code)
+		if err := buildBlog(ctx, components, out, cache, langSite, langPosts, translations, indexAlternates, authors); err != nil {
+			return site, err
+		}
 	}
 
-	if err := copyStaticFiles(opts); err != nil {
-		return err
+	if err := buildAuthors(ctx, components, out, cache, site, authorPosts); err != nil {
+		return site, err
+	}
+
+	if cache.minify != nil {
+		if files, before, after := cache.minify.Stats(); files > 0 {
+			slog.Info("minified output", "files", files, "bytes_before", before, "bytes_saved", before-after)
+		}
 	}
 
-	if err := GenerateSitemap(opts.OutputDir, opts.StaticDir, site.URL, publishedPosts); err != nil {
+	pages := sitemapPages(site, cache.pages, website.BuildTime(ctx))
+	if err := GenerateSitemap(out, site, pages); err != nil {
 		slog.Warn("failed to generate sitemap", "error", err)
 	}
 
-	if err := GenerateRobots(opts.OutputDir, opts.StaticDir, site.URL); err != nil {
-		slog.Warn("failed to generate robots.txt", "error", err)
+	if err := GenerateLLMs(out, site, llmsPosts(cache.pages)); err != nil {
+		return site, fmt.Errorf("generating llms.txt: %w", err)
 	}
 
-	slog.Info("build completed", "output", opts.OutputDir)
-	return nil
+	if err := GenerateRobots(out, site); err != nil {
+		return site, fmt.Errorf("generating robots.txt: %w", err)
+	}
+
+	// Templates run after the other generators, so they can replace
+	// generated files.
+	data := TemplateData{
+		Site:  site,
+		Posts: publishedPosts,
+		Pages: pages,
+		Build: BuildInfo{Time: website.BuildTime(ctx), Environment: site.Environment, Production: website.IsProduction(site)},
+	}
+	if err := renderTemplates(ctx, out, cache, opts.StaticFS, "static", data); err != nil {
+		return site, fmt.Errorf("rendering static templates: %w", err)
+	}
+	if err := renderTemplates(ctx, out, cache, opts.PublicFS, ".", data); err != nil {
+		return site, fmt.Errorf("rendering public templates: %w", err)
+	}
+
+	// _headers hashes the inline scripts of every page, including those
+	// rendered from templates, unless a template replaces it.
+	if site.Headers.Enabled && !hasTemplate(opts.PublicFS, "_headers") {
+		if err := GenerateHeaders(out, site); err != nil {
+			return site, fmt.Errorf("generating _headers: %w", err)
+		}
+	}
+
+	// Fonts are written after every page, which subsetting reads.
+	if err := writeFonts(out, cache, opts.FontsFS, site); err != nil {
+		return site, err
+	}
+
+	return site, nil
 }
 
 // loadSiteWithTheme loads site config and theme from the config directory.
-func loadSiteWithTheme(configDir string) (website.SiteConfig, error) {
-	site, err := website.LoadSiteConfig(filepath.Join(configDir, "site.yaml"))
+func loadSiteWithTheme(config fs.FS) (website.SiteConfig, error) {
+	site, err := website.LoadSiteConfigFS(config, "site.yaml")
 	if err != nil {
 		return website.SiteConfig{}, fmt.Errorf("loading site config: %w", err)
 	}
 
-	theme, err := website.LoadTheme(filepath.Join(configDir, "theme.yaml"))
+	theme, err := website.LoadThemeFS(config, "theme.yaml")
 	if err != nil {
 		slog.Warn("theme load failed, using defaults", "error", err)
 		theme = website.DefaultTheme()
 	}
 	site.Theme = theme
 
+	translations, err := website.LoadTranslationsFS(config, "i18n")
+	if err != nil {
+		return website.SiteConfig{}, fmt.Errorf("loading translations: %w", err)
+	}
+	site.Translations = translations
+
+	authors, err := website.LoadAuthorsFS(config, "authors.yaml")
+	if err != nil {
+		return website.SiteConfig{}, fmt.Errorf("loading authors: %w", err)
+	}
+	site.Authors = authors
+
 	return site, nil
 }
 
-// buildBlog renders the blog index and all published blog posts.
-func buildBlog(components ComponentRegistry, opts BuildOptions, site website.SiteConfig, published []markdown.Post) error {
+// buildBlog renders the blog index and all published blog posts of one language.
+// The site config must already be localized with website.ForLanguage.
+// Posts link to their author's page only when its ID is in authorPages.
+func buildBlog(ctx context.Context, components ComponentRegistry, out OutputFS, cache *buildCache, site website.SiteConfig, published []markdown.Post, translations map[string][]markdown.Post, indexAlternates []website.Alternate, authorPages map[string]bool) error {
 	if len(published) == 0 {
 		return nil
 	}
 
 	if components.BlogIndex != nil {
 		seo := website.SEO{
-			Title:       "Blog - " + site.Name,
+			Title:       website.T(site, "blog.title") + " - " + site.Name,
 			Description: site.Description,
 			IsBlogIndex: true,
+			Alternates:  indexAlternates,
 		}
-		indexPath := filepath.Join(opts.OutputDir, "blog", "index.html")
+		indexPath := path.Join(strings.TrimPrefix(website.LanguagePrefix(site), "/"), "blog", "index.html")
 
 		slog.Debug("rendering blog index", "path", indexPath, "posts", len(published))
 
-		if err := generator.RenderTemplComponent(indexPath, components.BlogIndex(site, seo, published)); err != nil {
+		if err := cache.render(ctx, out, indexPath, pageInputs{SEO: seo, Posts: published}, components.BlogIndex(site, seo, published)); err != nil {
 			return fmt.Errorf("rendering blog index: %w", err)
 		}
 	}
@@ -144,27 +368,128 @@ func buildBlog(components ComponentRegistry, opts BuildOptions, site website.Sit
 		return nil
 	}
 
+	var errs []error
 	for _, post := range published {
 		seo := website.SEO{
 			Title:                post.Meta.Title + " - " + site.Name,
 			Description:          post.Meta.Description,
 			IsArticle:            true,
-			ArticlePublishedTime: post.Meta.Date,
+			ArticlePublishedTime: formatISODate(post.Meta.Time),
 			ArticleAuthor:        post.Meta.Author,
+			Alternates:           postAlternates(site, translations[post.Meta.TranslationKey]),
+		}
+		if modified := postLastMod(site, post); modified.After(post.Meta.Time) {
+			seo.ArticleModifiedTime = formatISODate(modified)
+		}
+		if author, ok := website.ResolveAuthor(site, post.Meta.Author); ok {
+			seo.ArticleAuthor = author.Name
+			if authorPages[author.ID] {
+				seo.ArticleAuthorURL = website.AbsoluteURL(site, website.AuthorPath(author))
+			}
+		}
+		if err := applySEOOverrides(&seo, post.Meta.Extra); err != nil {
+			errs = append(errs, fmt.Errorf("post %s: %w", post.Meta.Slug, err))
+			continue
+		}
+		postPath := path.Join(strings.TrimPrefix(website.LanguagePrefix(site), "/"), "blog", post.Meta.Slug, "index.html")
+		if seo.OGImage == "" && cache.ogImages != nil {
+			if err := cache.ogImages.write(out, cache, site, post, &seo, path.Join(path.Dir(postPath), "og.png")); err != nil {
+				return fmt.Errorf("rendering og image of %s: %w", post.Meta.Slug, err)
+			}
 		}
-		postPath := filepath.Join(opts.OutputDir, "blog", post.Meta.Slug, "index.html")
 
 		slog.Debug("rendering blog post", "slug", post.Meta.Slug, "path", postPath)
 
-		if err := generator.RenderTemplComponent(postPath, components.BlogPost(site, seo, post)); err != nil {
+		if err := cache.render(ctx, out, postPath, pageInputs{SEO: seo, Posts: []markdown.Post{post}}, components.BlogPost(site, seo, post)); err != nil {
 			return fmt.Errorf("rendering blog post %s: %w", post.Meta.Slug, err)
 		}
+		if !seo.NoIndex {
+			mdPath := strings.TrimSuffix(postPath, ".html") + ".md"
+			if err := cache.writeFile(out, mdPath, PostMarkdown(site, website.OutputURLPath(postPath), post)); err != nil {
+				return fmt.Errorf("writing markdown mirror of %s: %w", post.Meta.Slug, err)
+			}
+		}
 	}
 
-	slog.Info("blog built", "posts", len(published))
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid post frontmatter: %w", err)
+	}
+
+	slog.Info("blog built", "language", website.GetLanguage(site), "posts", len(published))
 	return nil
 }
 
+// buildAuthors renders a profile page for every configured author with published posts.
+// Author pages are not localized: published holds the default-language posts only.
+func buildAuthors(ctx context.Context, components ComponentRegistry, out OutputFS, cache *buildCache, site website.SiteConfig, published []markdown.Post) error {
+	if components.AuthorPage == nil || len(site.Authors) == 0 {
+		return nil
+	}
+
+	rendered := 0
+	for _, author := range website.SortedAuthors(site) {
+		posts := filterAuthor(site, published, author.ID)
+		if len(posts) == 0 {
+			slog.Debug("skipping author without posts", "author", author.ID)
+			continue
+		}
+
+		seo := website.SEO{
+			Title:         author.Name + " - " + site.Name,
+			Description:   author.Bio,
+			OGType:        "profile",
+			OGImage:       author.Avatar,
+			ProfileAuthor: author.ID,
+			IsAuthorPage:  true,
+		}
+		authorPath := path.Join("authors", author.ID, "index.html")
+
+		slog.Debug("rendering author page", "author", author.ID, "path", authorPath, "posts", len(posts))
+
+		if err := cache.render(ctx, out, authorPath, pageInputs{SEO: seo, Author: author, Posts: posts}, components.AuthorPage(site, seo, author, posts)); err != nil {
+			return fmt.Errorf("rendering author page %s: %w", author.ID, err)
+		}
+		rendered++
+	}
+
+	slog.Info("authors built", "pages", rendered, "authors", len(site.Authors))
+	return nil
+}
+
+// authorPages returns the IDs of the authors buildAuthors renders a page for.
+func authorPages(components ComponentRegistry, site website.SiteConfig, published []markdown.Post) map[string]bool {
+	pages := make(map[string]bool)
+	if components.AuthorPage == nil {
+		return pages
+	}
+	for _, p := range published {
+		if a, ok := website.ResolveAuthor(site, p.Meta.Author); ok {
+			pages[a.ID] = true
+		}
+	}
+	return pages
+}
+
+// filterAuthor returns only posts whose author resolves to the given author ID.
+func filterAuthor(site website.SiteConfig, posts []markdown.Post, id string) []markdown.Post {
+	var filtered []markdown.Post
+	for _, p := range posts {
+		if a, ok := website.ResolveAuthor(site, p.Meta.Author); ok && a.ID == id {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// formatISODate formats a post time as RFC3339 for structured data.
+// Returns an empty string for zero time.
+func formatISODate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // filterPublished returns only posts with Published=true.
 func filterPublished(posts []markdown.Post) []markdown.Post {
 	var published []markdown.Post
@@ -176,21 +501,79 @@ func filterPublished(posts []markdown.Post) []markdown.Post {
 	return published
 }
 
-// copyStaticFiles copies the static directory to the output.
-func copyStaticFiles(opts BuildOptions) error {
-	src := opts.StaticDir
-	dst := filepath.Join(opts.OutputDir, "static")
+// pageInputs is the page-specific data a component renders from. Together
+// with the config hash it determines whether a page must be re-rendered.
+type pageInputs struct {
+	SEO    website.SEO
+	Author website.Author
+	Posts  []markdown.Post
+}
 
-	if _, err := os.Stat(src); os.IsNotExist(err) {
-		slog.Debug("no static directory found", "path", src)
-		return nil
+// copyStaticFiles copies changed files from the static input to static/ in the output.
+// Fingerprinted files are listed in assets.json, and the returned map links
+// their original URL paths to the fingerprinted ones.
+func copyStaticFiles(ctx context.Context, static fs.FS, out OutputFS, cache *buildCache, cfg website.AssetsConfig) (map[string]string, error) {
+	if _, err := fs.Stat(static, "."); errors.Is(err, fs.ErrNotExist) {
+		slog.Debug("no static directory found")
+		return nil, nil
+	}
+
+	slog.Debug("copying static files")
+
+	var fingerprint func(string) bool
+	if cfg.Fingerprint {
+		fingerprint = fingerprintFilter(cfg)
+	}
+	assets, err := cache.copyDir(ctx, out, static, "static", fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("copying static files: %w", err)
+	}
+	if len(assets) == 0 {
+		return nil, nil
 	}
 
-	slog.Debug("copying static files", "from", src, "to", dst)
+	manifest, err := json.MarshalIndent(assets, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding asset manifest: %w", err)
+	}
+	if err := cache.writeFile(out, "assets.json", manifest); err != nil {
+		return nil, fmt.Errorf("writing asset manifest: %w", err)
+	}
+	slog.Info("assets fingerprinted", "files", len(assets))
+	return assets, nil
+}
 
-	if err := generator.CopyDir(src, dst); err != nil {
-		return fmt.Errorf("copying static files: %w", err)
+// copyPublicFiles copies changed files from the public input to the root of
+// the output. Generated files with the same name replace them.
+func copyPublicFiles(ctx context.Context, public fs.FS, out OutputFS, cache *buildCache) error {
+	if public == nil {
+		return nil
+	}
+	if _, err := fs.Stat(public, "."); errors.Is(err, fs.ErrNotExist) {
+		slog.Debug("no public directory found")
+		return nil
 	}
 
+	slog.Debug("copying public files")
+
+	if _, err := cache.copyDir(ctx, out, public, ".", nil); err != nil {
+		return fmt.Errorf("copying public files: %w", err)
+	}
 	return nil
 }
+
+// fingerprintFilter reports which static files get fingerprinted names.
+// Files starting with "_" or "." are host configuration and keep theirs.
+func fingerprintFilter(cfg website.AssetsConfig) func(name string) bool {
+	exts := cfg.Extensions
+	if len(exts) == 0 {
+		exts = []string{".css", ".js"}
+	}
+	return func(name string) bool {
+		base := path.Base(name)
+		if strings.HasPrefix(base, "_") || strings.HasPrefix(base, ".") {
+			return false
+		}
+		return slices.Contains(exts, path.Ext(base))
+	}
+}
@@ -3,9 +3,12 @@ package engine
 import (
 	"context"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"maciejadamski/pkg/markdown"
 	"maciejadamski/pkg/website"
@@ -21,6 +24,9 @@ func TestDefaultOptions(t *testing.T) {
 	if opts.ConfigDir != "config" {
 		t.Errorf("ConfigDir = %v, want config", opts.ConfigDir)
 	}
+	if opts.PublicDir != "public" {
+		t.Errorf("PublicDir = %v, want public", opts.PublicDir)
+	}
 }
 
 func TestFilterPublished(t *testing.T) {
@@ -91,7 +97,7 @@ func TestBuild_Basic(t *testing.T) {
 		},
 	}
 
-	if err := Build(components, opts); err != nil {
+	if err := Build(context.Background(), components, opts); err != nil {
 		t.Fatalf("Build() error = %v", err)
 	}
 
@@ -113,8 +119,134 @@ func TestBuild_MissingConfig(t *testing.T) {
 
 	components := ComponentRegistry{}
 
-	err := Build(components, opts)
+	err := Build(context.Background(), components, opts)
 	if err == nil {
 		t.Error("Build() expected error for missing config, got nil")
 	}
 }
+
+func TestBuild_FailureKeepsPreviousOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	opts := BuildOptions{
+		OutputDir:  filepath.Join(tmpDir, "dist"),
+		ConfigDir:  filepath.Join(tmpDir, "config"),
+		ContentDir: filepath.Join(tmpDir, "content"),
+		StaticDir:  filepath.Join(tmpDir, "static"),
+	}
+	os.MkdirAll(opts.ConfigDir, 0755)
+	os.MkdirAll(filepath.Join(opts.ContentDir, "blog"), 0755)
+	os.WriteFile(filepath.Join(opts.ConfigDir, "site.yaml"), []byte("name: Test Site\nurl: http://test.com"), 0644)
+
+	components := ComponentRegistry{
+		Index: func(c website.SiteConfig, s website.SEO, posts []markdown.Post) templ.Component {
+			return mockComponent{content: "<h1>Home</h1>"}
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	// A post with invalid frontmatter fails the next build.
+	os.WriteFile(filepath.Join(opts.ContentDir, "blog", "bad.md"), []byte("---\ntitle: Bad\npublished: true\nog_type: nonsense\n---\nBody\n"), 0644)
+	components.BlogPost = func(c website.SiteConfig, s website.SEO, p markdown.Post) templ.Component {
+		return mockComponent{content: "post"}
+	}
+	if err := Build(context.Background(), components, opts); err == nil {
+		t.Fatal("Build() error = nil, want error for invalid post")
+	}
+
+	got, err := os.ReadFile(filepath.Join(opts.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("previous output missing after failed build: %v", err)
+	}
+	if string(got) != "<h1>Home</h1>" {
+		t.Errorf("index.html = %q, want previous content", got)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".dist-build-") {
+			t.Errorf("staging directory %s left behind", e.Name())
+		}
+	}
+}
+
+func TestBuild_PublicFiles(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.StaticFS = fstest.MapFS{
+		"css/site.css":     {Data: []byte("body {}")},
+		"sitemap.xml.tmpl": {Data: []byte("{{ range .Pages }}{{ .Path }}{{ end }}")},
+		".gitkeep":         {Data: []byte("")},
+	}
+	opts.PublicFS = fstest.MapFS{
+		"_redirects":               {Data: []byte("/old /new 301\n")},
+		"favicon.ico":              {Data: []byte("ico")},
+		".well-known/security.txt": {Data: []byte("Contact: mailto:a@example.com\n")},
+		".DS_Store":                {Data: []byte("junk")},
+		"notes/page.html.tmpl":     {Data: []byte("{{ .Site.Name }}")},
+	}
+	if err := Build(context.Background(), ComponentRegistry{}, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if err := fstest.TestFS(out, "_redirects", "favicon.ico", ".well-known/security.txt", "static/css/site.css", "sitemap.xml"); err != nil {
+		t.Errorf("output fs: %v", err)
+	}
+	for _, name := range []string{
+		".DS_Store", "notes/page.html.tmpl",
+		"static/sitemap.xml.tmpl", "static/.gitkeep", "static/_redirects",
+	} {
+		if _, err := fs.Stat(out, name); err == nil {
+			t.Errorf("%s was published", name)
+		}
+	}
+}
+
+func TestBuild_AuthorPages(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml":    {Data: []byte("name: Test\nurl: https://example.com\nlanguages:\n  - code: pl\n")},
+		"authors.yaml": {Data: []byte("jane:\n  name: Jane Doe\nidle:\n  name: Idle\nola:\n  name: Ola\n")},
+	}
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md":    {Data: []byte("---\ntitle: Hello\nauthor: jane\npublished: true\n---\nHi\n")},
+		"blog/hello.pl.md": {Data: []byte("---\ntitle: Cześć\nauthor: Jane Doe\npublished: true\n---\nHi\n")},
+		"blog/witaj.pl.md": {Data: []byte("---\ntitle: Witaj\nauthor: ola\npublished: true\n---\nHi\n")},
+	}
+	components := ComponentRegistry{
+		BlogPost: func(_ website.SiteConfig, seo website.SEO, _ markdown.Post) templ.Component {
+			return mockComponent{content: seo.ArticleAuthor + "|" + seo.ArticleAuthorURL}
+		},
+		AuthorPage: func(_ website.SiteConfig, seo website.SEO, _ website.Author, posts []markdown.Post) templ.Component {
+			var titles []string
+			for _, p := range posts {
+				titles = append(titles, p.Meta.Title)
+			}
+			return mockComponent{content: seo.ProfileAuthor + "|" + seo.ArticleAuthor + "|" + strings.Join(titles, ",")}
+		},
+	}
+
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if page, _ := fs.ReadFile(out, "authors/jane/index.html"); string(page) != "jane||Hello" {
+		t.Errorf("author page = %q, want the profiled author and default-language posts", page)
+	}
+	if _, err := fs.Stat(out, "authors/idle/index.html"); err == nil {
+		t.Error("author page rendered for an author without posts")
+	}
+	if _, err := fs.Stat(out, "authors/ola/index.html"); err == nil {
+		t.Error("author page rendered for an author without default-language posts")
+	}
+	for name, want := range map[string]string{
+		"pl/blog/hello/index.html": "Jane Doe|https://example.com/authors/jane/",
+		"pl/blog/witaj/index.html": "Ola|",
+	} {
+		if page, _ := fs.ReadFile(out, name); string(page) != want {
+			t.Errorf("%s = %q, want %q", name, page, want)
+		}
+	}
+}
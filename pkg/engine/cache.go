@@ -0,0 +1,394 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"maciejadamski/pkg/generator"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+// cacheVersion is bumped whenever the manifest layout or hashing changes.
+const cacheVersion = 2
+
+// cacheManifest records the input hash of every output written by a build.
+type cacheManifest struct {
+	Version int `json:"version"`
+	// Config hashes the config files, the templ version, the build binary,
+	// the environment and the output directory.
+	Config string `json:"config"`
+	// Outputs maps a path relative to the output directory to its input hash.
+	Outputs map[string]string `json:"outputs"`
+}
+
+// buildCache decides which outputs must be rewritten. Pages are keyed by a
+// hash of the data they are rendered from, static files by their content.
+// When the config hash changes, or no cache directory is set, every output
+// is rewritten. Caching only applies to disk outputs, which persist between
+// builds.
+type buildCache struct {
+	dir     string
+	plugins pluginList
+	full    bool
+	prev    cacheManifest
+	next    cacheManifest
+	skipped int
+	written int
+
+	// minify, when set, minifies rendered pages and static CSS.
+	minify *generator.Minifier
+	// assets, when set, rewrites asset references in rendered pages.
+	// assetsHash hashes the asset manifest the pages are rendered with.
+	assets     *generator.AssetRewriter
+	assetsHash string
+	// ogImages, when set, renders Open Graph images for posts.
+	ogImages *ogImageRenderer
+
+	// pages lists every page of this build, rendered or kept.
+	pages []renderedPage
+}
+
+// renderedPage is a page of the current build and the data it renders from.
+type renderedPage struct {
+	// Path is the URL path the page is served at.
+	Path   string
+	Inputs pageInputs
+}
+
+// openCache loads the manifest from opts.CacheDir and compares its config hash.
+// opts must have its defaults applied.
+func openCache(opts BuildOptions) (*buildCache, error) {
+	h := sha256.New()
+	if err := hashConfig(h, opts.ConfigFS); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(h, "environment %s\n", opts.Environment)
+	disk, isDisk := opts.Output.(*DiskOutput)
+	if isDisk {
+		abs, err := filepath.Abs(disk.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("resolving output directory: %w", err)
+		}
+		fmt.Fprintf(h, "output %s\n", abs)
+	}
+	configHash := hex.EncodeToString(h.Sum(nil))
+
+	c := &buildCache{
+		plugins: opts.Plugins,
+		full:    true,
+		next:    cacheManifest{Version: cacheVersion, Config: configHash, Outputs: make(map[string]string)},
+	}
+	if isDisk {
+		c.dir = opts.CacheDir
+	}
+	if c.dir == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(c.manifestPath())
+	switch {
+	case os.IsNotExist(err):
+		slog.Debug("no build cache found", "path", c.manifestPath())
+		return c, nil
+	case err != nil:
+		return nil, fmt.Errorf("reading build cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.prev); err != nil {
+		slog.Warn("ignoring corrupt build cache", "path", c.manifestPath(), "error", err)
+		return c, nil
+	}
+
+	c.full = c.prev.Version != cacheVersion || c.prev.Config != configHash
+	if c.full {
+		slog.Info("config changed, running full build")
+	}
+	return c, nil
+}
+
+func (c *buildCache) manifestPath() string {
+	return filepath.Join(c.dir, "manifest.json")
+}
+
+// render writes component to name in out unless the inputs hash matches the
+// previous build and the file still exists. Rendered HTML passes through the
+// OnPageRendered plugin hooks, the asset rewriter and the minifier before it
+// is written. The component's context
+// carries the page URL path (website.PagePath).
+func (c *buildCache) render(ctx context.Context, out OutputFS, name string, inputs pageInputs, component templ.Component) error {
+	if err := checkCanceled(ctx, "rendering"); err != nil {
+		return err
+	}
+	c.pages = append(c.pages, renderedPage{Path: website.OutputURLPath(name), Inputs: inputs})
+
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return fmt.Errorf("hashing inputs of %s: %w", name, err)
+	}
+	// Pages embed asset URLs, so a changed fingerprint re-renders them.
+	if c.fresh(out, name, hashBytes(append(data, c.assetsHash...))) {
+		return nil
+	}
+
+	slog.Debug("rendering component", "path", name)
+	ctx = website.WithPagePath(ctx, website.OutputURLPath(name))
+	err = out.WriteFile(name, func(w io.Writer) error {
+		if len(c.plugins) == 0 && c.minify == nil && c.assets == nil {
+			if err := component.Render(ctx, w); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+
+		var buf bytes.Buffer
+		if err := component.Render(ctx, &buf); err != nil {
+			return err
+		}
+		html, err := c.plugins.pageRendered(name, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if c.assets != nil {
+			html = c.assets.Rewrite(html)
+		}
+		if c.minify != nil {
+			html = c.minify.HTML(html)
+		}
+		_, err = w.Write(html)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rendering component to %s: %w", name, err)
+	}
+	c.written++
+	return nil
+}
+
+// copyDir copies changed files from src into dir in out, skipping
+// dotfiles and template sources. CSS files are minified when the minifier is
+// set. Files for which fingerprint returns
+// true are written under a content-hashed name; the returned map links their
+// original URL paths to the fingerprinted ones.
+func (c *buildCache) copyDir(ctx context.Context, out OutputFS, src fs.FS, dir string, fingerprint func(name string) bool) (map[string]string, error) {
+	assets := make(map[string]string)
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := checkCanceled(ctx, "copying static files"); err != nil {
+			return err
+		}
+		if unpublished(name) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		if c.minify != nil && path.Ext(name) == ".css" {
+			data = c.minify.CSS(data)
+		}
+		outName := path.Join(dir, name)
+		if fingerprint != nil && fingerprint(name) {
+			fpName := generator.FingerprintName(outName, data)
+			assets["/"+outName] = "/" + fpName
+			outName = fpName
+		}
+		if err := c.writeFile(out, outName, data); err != nil {
+			return fmt.Errorf("copy %s: %w", name, err)
+		}
+		return nil
+	})
+	return assets, err
+}
+
+// unpublished reports whether a source file or directory is never copied to
+// the output: hidden files and *.tmpl template sources.
+func unpublished(name string) bool {
+	return hidden(name) || path.Ext(name) == ".tmpl"
+}
+
+// hidden reports whether a source path is a dotfile such as .DS_Store or
+// .git. The .well-known directory is not hidden.
+func hidden(name string) bool {
+	if name == "." || name == ".well-known" {
+		return false
+	}
+	return strings.HasPrefix(path.Base(name), ".")
+}
+
+// setAssets makes rendered pages reference the fingerprinted asset paths.
+func (c *buildCache) setAssets(assets map[string]string) error {
+	if len(assets) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(assets)
+	if err != nil {
+		return fmt.Errorf("hashing asset manifest: %w", err)
+	}
+	c.assets = generator.NewAssetRewriter(assets)
+	c.assetsHash = hashBytes(data)
+	return nil
+}
+
+// writeFile writes data to name in out unless it is unchanged since the
+// previous build. Files written this way are removed once no longer produced.
+func (c *buildCache) writeFile(out OutputFS, name string, data []byte) error {
+	if c.fresh(out, name, hashBytes(data)) {
+		return nil
+	}
+	if err := out.WriteFile(name, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+	c.written++
+	return nil
+}
+
+// generate writes the output of render to name in out unless key, which
+// must cover everything render depends on, is unchanged since the previous
+// build. Files written this way are removed once no longer produced.
+func (c *buildCache) generate(out OutputFS, name string, key []byte, render func() ([]byte, error)) error {
+	if c.fresh(out, name, hashBytes(key)) {
+		return nil
+	}
+	data, err := render()
+	if err != nil {
+		return err
+	}
+	if err := out.WriteFile(name, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+	c.written++
+	return nil
+}
+
+// fresh records hash for name and reports whether the existing output can be kept.
+func (c *buildCache) fresh(out OutputFS, name, hash string) bool {
+	c.next.Outputs[name] = hash
+	if c.full || c.prev.Outputs[name] != hash {
+		return false
+	}
+	if _, err := fs.Stat(out, name); err != nil {
+		return false
+	}
+	c.skipped++
+	return true
+}
+
+// track returns out with every written file recorded in the manifest, so
+// generated files are pruned once no longer produced.
+func (c *buildCache) track(out OutputFS) OutputFS {
+	return trackedOutput{OutputFS: out, c: c}
+}
+
+type trackedOutput struct {
+	OutputFS
+	c *buildCache
+}
+
+func (t trackedOutput) WriteFile(name string, write func(w io.Writer) error) error {
+	if _, ok := t.c.next.Outputs[name]; !ok {
+		t.c.next.Outputs[name] = ""
+	}
+	return t.OutputFS.WriteFile(name, write)
+}
+
+// prune removes outputs of the previous build that were not produced again.
+func (c *buildCache) prune(out OutputFS) error {
+	slog.Info("build cache", "written", c.written, "unchanged", c.skipped, "full", c.full)
+
+	var stale []string
+	for name := range c.prev.Outputs {
+		if _, ok := c.next.Outputs[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		slog.Debug("removing stale output", "path", name)
+		if err := out.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing stale output %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// save writes the manifest. It must only be called once the outputs it
+// describes are in place.
+func (c *buildCache) save() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c.next, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding build cache: %w", err)
+	}
+	if err := generator.WriteFileAtomic(c.manifestPath(), 0644, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return fmt.Errorf("writing build cache: %w", err)
+	}
+	return nil
+}
+
+// hashConfig writes to h a hash of every config file together with the
+// templ version and the running binary, which embeds the compiled templates.
+func hashConfig(h io.Writer, config fs.FS) error {
+	fmt.Fprintf(h, "templ %s\n", templ.Version())
+
+	if exe, err := os.Executable(); err == nil {
+		if data, err := os.ReadFile(exe); err == nil {
+			fmt.Fprintf(h, "binary %s\n", hashBytes(data))
+		}
+	}
+
+	err := fs.WalkDir(config, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(config, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %s\n", name, hashBytes(data))
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("hashing config: %w", err)
+	}
+	return nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
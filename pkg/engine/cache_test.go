@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+// countingComponent records how many times each page was rendered.
+type countingComponent struct {
+	renders map[string]int
+	name    string
+}
+
+func (c countingComponent) Render(ctx context.Context, w io.Writer) error {
+	c.renders[c.name]++
+	_, err := io.WriteString(w, c.name)
+	return err
+}
+
+func TestBuild_Incremental(t *testing.T) {
+	tmpDir := t.TempDir()
+	opts := BuildOptions{
+		OutputDir:  filepath.Join(tmpDir, "dist"),
+		ConfigDir:  filepath.Join(tmpDir, "config"),
+		ContentDir: filepath.Join(tmpDir, "content"),
+		StaticDir:  filepath.Join(tmpDir, "static"),
+		CacheDir:   filepath.Join(tmpDir, "tmp", ".buildcache"),
+	}
+	blogDir := filepath.Join(opts.ContentDir, "blog")
+	for _, dir := range []string{opts.ConfigDir, blogDir, opts.StaticDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(opts.ConfigDir, "site.yaml"), "name: Test\nurl: https://example.com\n")
+	write(filepath.Join(blogDir, "a.md"), "---\ntitle: A\npublished: true\n---\nA\n")
+	write(filepath.Join(blogDir, "b.md"), "---\ntitle: B\npublished: true\n---\nB\n")
+	write(filepath.Join(opts.StaticDir, "style.css"), "body {}")
+
+	renders := map[string]int{}
+	components := ComponentRegistry{
+		BlogIndex: func(_ website.SiteConfig, _ website.SEO, _ []markdown.Post) templ.Component {
+			return countingComponent{renders: renders, name: "index"}
+		},
+		BlogPost: func(_ website.SiteConfig, _ website.SEO, p markdown.Post) templ.Component {
+			return countingComponent{renders: renders, name: p.Meta.Slug}
+		},
+	}
+	build := func() map[string]int {
+		t.Helper()
+		clear(renders)
+		if err := Build(context.Background(), components, opts); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		return maps.Clone(renders)
+	}
+
+	if got := build(); got["index"] != 1 || got["a"] != 1 || got["b"] != 1 {
+		t.Fatalf("first build renders = %v, want all pages", got)
+	}
+
+	if got := build(); len(got) != 0 {
+		t.Errorf("unchanged build renders = %v, want none", got)
+	}
+
+	write(filepath.Join(blogDir, "a.md"), "---\ntitle: A\npublished: true\n---\nA changed\n")
+	if got := build(); got["a"] != 1 || got["b"] != 0 || got["index"] != 1 {
+		t.Errorf("after editing a.md renders = %v, want a and index only", got)
+	}
+
+	if err := os.Remove(filepath.Join(blogDir, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+	build()
+	if _, err := os.Stat(filepath.Join(opts.OutputDir, "blog", "b", "index.html")); !os.IsNotExist(err) {
+		t.Errorf("stale output for deleted post still exists: %v", err)
+	}
+
+	write(filepath.Join(opts.ConfigDir, "site.yaml"), "name: Renamed\nurl: https://example.com\n")
+	if got := build(); got["a"] != 1 || got["index"] != 1 {
+		t.Errorf("after config change renders = %v, want full build", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(opts.OutputDir, "static", "style.css")); err != nil {
+		t.Errorf("static file missing after full build: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CanceledError is returned by Build when its context is canceled or times
+// out. It unwraps to the context error, so errors.Is(err, context.Canceled)
+// also holds.
+type CanceledError struct {
+	// Stage names the build stage that was interrupted.
+	Stage string
+	Err   error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("build canceled during %s: %v", e.Stage, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// checkCanceled returns a CanceledError for stage if ctx is done.
+func checkCanceled(ctx context.Context, stage string) error {
+	if err := ctx.Err(); err != nil {
+		return &CanceledError{Stage: stage, Err: err}
+	}
+	return nil
+}
+
+// asCanceled converts err to a CanceledError when it was caused by ctx
+// being done, so callers get one error type regardless of where the
+// cancellation was noticed.
+func asCanceled(ctx context.Context, stage string, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	var ce *CanceledError
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &CanceledError{Stage: stage, Err: ctx.Err()}
+}
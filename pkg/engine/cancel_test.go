@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+func TestBuild_Canceled(t *testing.T) {
+	opts, out := memoryTestOptions()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	components := ComponentRegistry{
+		// Cancel while the homepage renders, as a newer file change would.
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				cancel()
+				_, err := io.WriteString(w, "partial")
+				return err
+			})
+		},
+	}
+
+	err := Build(ctx, components, opts)
+	var canceled *CanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("Build() error = %v, want *CanceledError", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Build() error = %v, want wrapping context.Canceled", err)
+	}
+	if _, err := fs.Stat(out, "index.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("output committed by canceled build")
+	}
+}
+
+func TestBuild_ContextValues(t *testing.T) {
+	opts, out := memoryTestOptions()
+
+	var gotPath string
+	var gotTime bool
+	components := ComponentRegistry{
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				gotPath = website.PagePath(ctx)
+				gotTime = !website.BuildTime(ctx).IsZero()
+				return nil
+			})
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if gotPath != "/" {
+		t.Errorf("PagePath() = %q, want /", gotPath)
+	}
+	if !gotTime {
+		t.Error("BuildTime() not set")
+	}
+	if _, err := fs.Stat(out, "index.html"); err != nil {
+		t.Errorf("index.html missing: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"maciejadamski/pkg/generator"
+	"maciejadamski/pkg/website"
+
+	"golang.org/x/net/html"
+)
+
+// fontsDir is the output directory of the self-hosted fonts and their
+// stylesheet. Fonts are not fingerprinted: subsetting changes their content
+// after the pages referring to them are rendered, so they are cached briefly.
+const fontsDir = "fonts"
+
+// fontsStylesheet holds the @font-face rules of the self-hosted fonts.
+const fontsStylesheet = fontsDir + "/fonts.css"
+
+// baseRunes are always kept in subset fonts, so that pages using only these
+// characters still render in the font while an older cached subset is served.
+const baseRunes = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~" +
+	" –—‘’“”…•·"
+
+// subsetAttributes hold text that is shown on the page or by the browser.
+var subsetAttributes = []string{"alt", "title", "placeholder", "aria-label", "value"}
+
+// fontAssets returns the URLs of the self-hosted fonts the site loads.
+func fontAssets(site website.SiteConfig) website.FontAssets {
+	var assets website.FontAssets
+	for _, font := range website.LocalFonts(site) {
+		assets.Stylesheet = "/" + fontsStylesheet
+		for _, face := range font.Faces {
+			if face.Preload {
+				_, mimeType, _ := website.FontFormat(face.File)
+				assets.Preload = append(assets.Preload, website.FontPreload{URL: fontURL(face.File), Type: mimeType})
+			}
+		}
+	}
+	return assets
+}
+
+func fontURL(file string) string {
+	return "/" + path.Join(fontsDir, file)
+}
+
+// fontFaceCSS returns the @font-face rules of fonts.
+func fontFaceCSS(fonts []website.LocalFont) []byte {
+	var b bytes.Buffer
+	b.WriteString("/* Generated from the fonts section of site.yaml. */\n")
+	for _, font := range fonts {
+		for _, face := range font.Faces {
+			format, _, _ := website.FontFormat(face.File)
+			weight, style := face.Weight, face.Style
+			if weight == "" {
+				weight = "400"
+			}
+			if style == "" {
+				style = "normal"
+			}
+			fmt.Fprintf(&b, "@font-face {\n\tfont-family: %s;\n\tsrc: url(%s) format(%q);\n\tfont-weight: %s;\n\tfont-style: %s;\n\tfont-display: swap;\n}\n",
+				cssString(font.Family), cssString(fontURL(face.File)), format, weight, style)
+		}
+	}
+	return b.Bytes()
+}
+
+func cssString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// writeFonts copies the self-hosted fonts the site loads from fonts to the
+// output and writes their stylesheet. With site.Fonts.Subset, TrueType fonts
+// keep only the glyphs of the text in the generated pages, so it runs after
+// all pages are rendered.
+func writeFonts(out OutputFS, cache *buildCache, fonts fs.FS, site website.SiteConfig) error {
+	local := website.LocalFonts(site)
+	if len(local) == 0 {
+		return nil
+	}
+
+	var runes []rune
+	if site.Fonts.Subset {
+		var err error
+		if runes, err = pageRunes(out); err != nil {
+			return fmt.Errorf("collecting text for font subsetting: %w", err)
+		}
+	}
+
+	for _, font := range local {
+		for _, face := range font.Faces {
+			data, err := loadFont(fonts, face.File, nil)
+			if err != nil {
+				return fmt.Errorf("fonts: %s: %w", font.Family, err)
+			}
+			name := path.Join(fontsDir, face.File)
+			if !site.Fonts.Subset {
+				if err := cache.writeFile(out, name, data); err != nil {
+					return err
+				}
+				continue
+			}
+			key := append([]byte(hashBytes(data)), string(runes)...)
+			err = cache.generate(out, name, key, func() ([]byte, error) {
+				subset, err := generator.SubsetFont(data, runes)
+				if errors.Is(err, generator.ErrSubsetUnsupported) {
+					slog.Info("font not subset", "file", face.File, "reason", err)
+					return data, nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("subsetting %s: %w", face.File, err)
+				}
+				slog.Debug("subset font", "file", face.File, "bytes_before", len(data), "bytes_after", len(subset))
+				return subset, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return cache.writeFile(out, fontsStylesheet, fontFaceCSS(local))
+}
+
+// pageRunes returns the sorted characters of baseRunes and of the text and
+// shown attributes of every HTML page in out, with their upper- and
+// lowercase forms for CSS text-transform. Script and style contents are
+// skipped.
+func pageRunes(out fs.FS) ([]rune, error) {
+	seen := make(map[rune]bool)
+	add := func(s string) {
+		for _, r := range s {
+			seen[r] = true
+			seen[unicode.ToUpper(r)] = true
+			seen[unicode.ToLower(r)] = true
+		}
+	}
+	add(baseRunes)
+
+	err := fs.WalkDir(out, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		page, err := fs.ReadFile(out, name)
+		if err != nil {
+			return err
+		}
+
+		z := html.NewTokenizer(bytes.NewReader(page))
+		skip := false
+		for {
+			switch z.Next() {
+			case html.ErrorToken:
+				return nil
+			case html.StartTagToken, html.SelfClosingTagToken:
+				token := z.Token()
+				skip = token.Data == "script" || token.Data == "style"
+				for _, attr := range token.Attr {
+					if slices.Contains(subsetAttributes, attr.Key) {
+						add(attr.Val)
+					}
+				}
+			case html.EndTagToken:
+				skip = false
+			case html.TextToken:
+				if !skip {
+					add(string(z.Text()))
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runes := make([]rune, 0, len(seen))
+	for r := range seen {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	return runes, nil
+}
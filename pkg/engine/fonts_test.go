@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+const fontsSiteYAML = `name: Test
+url: https://example.com
+font_family: [go, jetbrains-mono]
+fonts:
+  subset: true
+  families:
+    go:
+      family: Go
+      faces:
+        - file: go/Go-Regular.ttf
+          preload: true
+        - file: Go-Italic.woff2
+          weight: "100 900"
+          style: italic
+`
+
+// fontComponents render the font assets of the site followed by some text.
+func fontComponents() ComponentRegistry {
+	return ComponentRegistry{
+		Index: func(site website.SiteConfig, _ website.SEO, _ []markdown.Post) templ.Component {
+			return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+				var b strings.Builder
+				b.WriteString(site.FontAssets.Stylesheet)
+				for _, p := range site.FontAssets.Preload {
+					b.WriteString(" " + p.URL + " " + p.Type)
+				}
+				b.WriteString(`<p title="Ω">Zażółć</p><script>var λ = 1</script>`)
+				_, err := io.WriteString(w, b.String())
+				return err
+			})
+		},
+	}
+}
+
+func TestBuild_Fonts(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{"site.yaml": {Data: []byte(fontsSiteYAML)}}
+	woff2 := []byte("wOF2 not really a font")
+	opts.FontsFS = fstest.MapFS{
+		"go/Go-Regular.ttf": {Data: goregular.TTF},
+		"Go-Italic.woff2":   {Data: woff2},
+	}
+
+	if err := Build(context.Background(), fontComponents(), opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	page, _ := fs.ReadFile(out, "index.html")
+	if want := "/fonts/fonts.css /fonts/go/Go-Regular.ttf font/ttf<p"; !strings.HasPrefix(string(page), want) {
+		t.Errorf("index.html = %q, want prefix %q", page, want)
+	}
+
+	css, err := fs.ReadFile(out, "fonts/fonts.css")
+	if err != nil {
+		t.Fatalf("fonts.css not written: %v", err)
+	}
+	for _, want := range []string{
+		`font-family: "Go";`,
+		`src: url("/fonts/go/Go-Regular.ttf") format("truetype");`,
+		"font-weight: 400;",
+		`src: url("/fonts/Go-Italic.woff2") format("woff2");`,
+		"font-weight: 100 900;\n\tfont-style: italic;",
+	} {
+		if !strings.Contains(string(css), want) {
+			t.Errorf("fonts.css missing %q:\n%s", want, css)
+		}
+	}
+
+	if data, _ := fs.ReadFile(out, "fonts/Go-Italic.woff2"); !bytes.Equal(data, woff2) {
+		t.Error("WOFF2 font not copied unchanged")
+	}
+	data, err := fs.ReadFile(out, "fonts/go/Go-Regular.ttf")
+	if err != nil {
+		t.Fatalf("TrueType font not written: %v", err)
+	}
+	if len(data) >= len(goregular.TTF) {
+		t.Errorf("subset font is %d bytes, want less than %d", len(data), len(goregular.TTF))
+	}
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		t.Fatalf("parsing subset font: %v", err)
+	}
+	var buf sfnt.Buffer
+	for r, want := range map[rune]bool{'ż': true, 'Ż': true, 'Ł': true, 'Ω': true, 'ω': true, 'λ': false, 'Λ': false} {
+		id, _ := f.GlyphIndex(&buf, r)
+		if got := id != 0; got != want {
+			t.Errorf("glyph %q mapped = %v, want %v", r, got, want)
+		}
+	}
+}
+
+func TestBuild_FontsMissingFile(t *testing.T) {
+	opts, _ := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{"site.yaml": {Data: []byte(fontsSiteYAML)}}
+	opts.FontsFS = fstest.MapFS{"go/Go-Regular.ttf": {Data: goregular.TTF}}
+
+	err := Build(context.Background(), fontComponents(), opts)
+	if err == nil || !strings.Contains(err.Error(), "Go-Italic.woff2") {
+		t.Errorf("Build() error = %v, want the missing font", err)
+	}
+}
+
+func TestBuild_GoogleFontsOnly(t *testing.T) {
+	opts, out := memoryTestOptions()
+	if err := Build(context.Background(), fontComponents(), opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if page, _ := fs.ReadFile(out, "index.html"); !strings.HasPrefix(string(page), "<p") {
+		t.Errorf("index.html = %q, want no font assets", page)
+	}
+	if _, err := fs.Stat(out, "fonts/fonts.css"); err == nil {
+		t.Error("fonts.css written without self-hosted fonts")
+	}
+}
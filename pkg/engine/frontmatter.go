@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"maciejadamski/pkg/website"
+)
+
+// ogTypes lists the Open Graph types a post may declare.
+var ogTypes = []string{"article", "website", "profile", "book", "video.other"}
+
+// twitterHandle matches a Twitter/X handle such as "@maciejadamski".
+var twitterHandle = regexp.MustCompile(`^@[A-Za-z0-9_]{1,15}$`)
+
+// applySEOOverrides maps per-post frontmatter keys onto seo.
+// All invalid values are reported together; valid ones are still applied.
+func applySEOOverrides(seo *website.SEO, extra map[string]any) error {
+	var errs []error
+	invalid := func(key string, value any, reason string) {
+		errs = append(errs, fmt.Errorf("frontmatter %q: invalid value %v: %s", key, value, reason))
+	}
+
+	if v, ok := extra["image"]; ok {
+		if s, ok := v.(string); ok && isValidURLOrPath(s) {
+			seo.OGImage = s
+		} else {
+			invalid("image", v, "must be a root-relative path or absolute URL")
+		}
+	}
+	if v, ok := extra["image_alt"]; ok {
+		if s, ok := v.(string); ok && s != "" {
+			seo.OGImageAlt = s
+		} else {
+			invalid("image_alt", v, "must be a non-empty string")
+		}
+	}
+	for key, dst := range map[string]*string{"image_width": &seo.OGImageWidth, "image_height": &seo.OGImageHeight} {
+		if v, ok := extra[key]; ok {
+			if n, ok := positiveInt(v); ok {
+				*dst = strconv.Itoa(n)
+			} else {
+				invalid(key, v, "must be a positive number of pixels")
+			}
+		}
+	}
+	if v, ok := extra["canonical"]; ok {
+		if s, ok := v.(string); ok && isValidURLOrPath(s) {
+			seo.Canonical = s
+		} else {
+			invalid("canonical", v, "must be a root-relative path or absolute URL")
+		}
+	}
+	if v, ok := extra["noindex"]; ok {
+		if b, ok := v.(bool); ok {
+			seo.NoIndex = b
+		} else {
+			invalid("noindex", v, "must be true or false")
+		}
+	}
+	if v, ok := extra["og_type"]; ok {
+		if s, ok := v.(string); ok && slices.Contains(ogTypes, s) {
+			seo.OGType = s
+		} else {
+			invalid("og_type", v, "must be one of "+strings.Join(ogTypes, ", "))
+		}
+	}
+	if v, ok := extra["section"]; ok {
+		if s, ok := v.(string); ok && s != "" {
+			seo.ArticleSection = s
+		} else {
+			invalid("section", v, "must be a non-empty string")
+		}
+	}
+	if v, ok := extra["tags"]; ok {
+		if tags, ok := stringList(v); ok {
+			seo.ArticleTags = tags
+		} else {
+			invalid("tags", v, "must be a list of strings or a comma-separated string")
+		}
+	}
+	if v, ok := extra["twitter_creator"]; ok {
+		if s, ok := v.(string); ok && twitterHandle.MatchString(s) {
+			seo.TwitterCreator = s
+		} else {
+			invalid("twitter_creator", v, `must be a handle like "@name"`)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isValidURLOrPath reports whether s is a root-relative path or an absolute http(s) URL.
+func isValidURLOrPath(s string) bool {
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// positiveInt converts a YAML or TOML integer, a whole JSON number or a
+// numeric string to a positive int.
+func positiveInt(v any) (int, bool) {
+	var n int
+	switch t := v.(type) {
+	case int:
+		n = t
+	case int64:
+		if t < 1 || t > math.MaxInt32 {
+			return 0, false
+		}
+		n = int(t)
+	case float64:
+		if t != math.Trunc(t) || t < 1 || t > math.MaxInt32 {
+			return 0, false
+		}
+		n = int(t)
+	case string:
+		var err error
+		if n, err = strconv.Atoi(strings.TrimSpace(t)); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return n, n > 0
+}
+
+// stringList converts a YAML list or comma-separated string into trimmed, non-empty strings.
+func stringList(v any) ([]string, bool) {
+	var raw []string
+	switch t := v.(type) {
+	case string:
+		raw = strings.Split(t, ",")
+	case []string:
+		raw = t
+	case []any:
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			raw = append(raw, s)
+		}
+	default:
+		return nil, false
+	}
+
+	var list []string
+	for _, s := range raw {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list, true
+}
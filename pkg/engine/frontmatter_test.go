@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+func TestApplySEOOverrides(t *testing.T) {
+	extra := map[string]any{
+		"image":           "/static/posts/cover.png",
+		"image_alt":       "Cover",
+		"image_width":     800,
+		"image_height":    "600",
+		"canonical":       "https://example.org/original/",
+		"noindex":         true,
+		"og_type":         "article",
+		"section":         "Go",
+		"tags":            []any{"go", " testing "},
+		"twitter_creator": "@jane",
+	}
+
+	var seo website.SEO
+	if err := applySEOOverrides(&seo, extra); err != nil {
+		t.Fatalf("applySEOOverrides() error = %v", err)
+	}
+
+	if seo.OGImage != "/static/posts/cover.png" {
+		t.Errorf("OGImage = %q, want /static/posts/cover.png", seo.OGImage)
+	}
+	if seo.OGImageAlt != "Cover" {
+		t.Errorf("OGImageAlt = %q, want Cover", seo.OGImageAlt)
+	}
+	if seo.OGImageWidth != "800" || seo.OGImageHeight != "600" {
+		t.Errorf("OGImage size = %qx%q, want 800x600", seo.OGImageWidth, seo.OGImageHeight)
+	}
+	if seo.Canonical != "https://example.org/original/" {
+		t.Errorf("Canonical = %q, want https://example.org/original/", seo.Canonical)
+	}
+	if !seo.NoIndex {
+		t.Error("NoIndex = false, want true")
+	}
+	if seo.OGType != "article" {
+		t.Errorf("OGType = %q, want article", seo.OGType)
+	}
+	if seo.ArticleSection != "Go" {
+		t.Errorf("ArticleSection = %q, want Go", seo.ArticleSection)
+	}
+	if len(seo.ArticleTags) != 2 || seo.ArticleTags[1] != "testing" {
+		t.Errorf("ArticleTags = %v, want [go testing]", seo.ArticleTags)
+	}
+	if seo.TwitterCreator != "@jane" {
+		t.Errorf("TwitterCreator = %q, want @jane", seo.TwitterCreator)
+	}
+}
+
+func TestApplySEOOverrides_Invalid(t *testing.T) {
+	extra := map[string]any{
+		"image":           "cover.png",
+		"image_width":     0,
+		"image_height":    "tall",
+		"canonical":       "ftp://example.org/",
+		"noindex":         "yes",
+		"og_type":         "blog",
+		"tags":            []any{"go", 42},
+		"twitter_creator": "jane",
+		"section":         "Go",
+	}
+
+	var seo website.SEO
+	err := applySEOOverrides(&seo, extra)
+	if err == nil {
+		t.Fatal("applySEOOverrides() expected error, got nil")
+	}
+	for _, key := range []string{"image", "image_width", "image_height", "canonical", "noindex", "og_type", "tags", "twitter_creator"} {
+		if !strings.Contains(err.Error(), `"`+key+`"`) {
+			t.Errorf("error %q does not report %s", err, key)
+		}
+	}
+	if seo.ArticleSection != "Go" {
+		t.Errorf("ArticleSection = %q, want valid values still applied", seo.ArticleSection)
+	}
+}
+
+func TestApplySEOOverrides_ImageSizeFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		post    string
+		wantErr bool
+	}{
+		{name: "yaml", post: "---\ntitle: A\nimage_width: 1200\nimage_height: \"630\"\n---\nBody\n"},
+		{name: "toml", post: "+++\ntitle = \"A\"\nimage_width = 1200\nimage_height = 630\n+++\nBody\n"},
+		{name: "json", post: "{\"title\": \"A\", \"image_width\": 1200, \"image_height\": 630.0}\nBody\n"},
+		{name: "json fraction", post: "{\"title\": \"A\", \"image_width\": 1200.5, \"image_height\": 630}\nBody\n", wantErr: true},
+		{name: "toml negative", post: "+++\ntitle = \"A\"\nimage_width = -1\nimage_height = 630\n+++\nBody\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"post.md": {Data: []byte(tt.post)}}
+			post, err := markdown.ParseFileFS(fsys, "post.md", markdown.Options{})
+			if err != nil {
+				t.Fatalf("ParseFileFS() error = %v", err)
+			}
+			var seo website.SEO
+			err = applySEOOverrides(&seo, post.Meta.Extra)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), `"image_width"`) {
+					t.Errorf("applySEOOverrides() error = %v, want image_width reported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applySEOOverrides() error = %v", err)
+			}
+			if seo.OGImageWidth != "1200" || seo.OGImageHeight != "630" {
+				t.Errorf("OGImage size = %qx%q, want 1200x630", seo.OGImageWidth, seo.OGImageHeight)
+			}
+		})
+	}
+}
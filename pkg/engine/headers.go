@@ -0,0 +1,345 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"maciejadamski/pkg/website"
+
+	"golang.org/x/net/html"
+)
+
+// defaultSecurityHeaders are sent with every response unless overridden by
+// headers.headers in the site config.
+var defaultSecurityHeaders = map[string]string{
+	"X-Frame-Options":        "DENY",
+	"X-Content-Type-Options": "nosniff",
+	"Referrer-Policy":        "strict-origin-when-cross-origin",
+	"Permissions-Policy":     "camera=(), microphone=(), geolocation=()",
+}
+
+// pageCacheControl is sent for pages and self-hosted fonts, whose URLs stay
+// the same when their content changes.
+const pageCacheControl = "public, max-age=3600"
+
+// assetCacheControl is sent for fingerprinted static files, whose URL
+// changes with their content.
+const assetCacheControl = "public, max-age=31536000, immutable"
+
+// maxHeaderRules is the number of _headers rules Cloudflare Pages applies.
+const maxHeaderRules = 100
+
+// defaultCacheRules apply when headers.cache is empty, together with the
+// rules for assets, pages and fonts from cacheRules.
+var defaultCacheRules = []website.CacheRule{
+	{Path: "/sitemap.xml", CacheControl: "public, max-age=86400"},
+	{Path: "/robots.txt", CacheControl: "public, max-age=86400"},
+	{Path: "/llms.txt", CacheControl: "public, max-age=86400"},
+	{Path: "/llms-full.txt", CacheControl: "public, max-age=86400"},
+}
+
+// cspDirectives lists the directives of the generated policy in order, with
+// their base sources.
+var cspDirectives = []struct {
+	name    string
+	sources []string
+}{
+	{"default-src", []string{"'self'"}},
+	{"script-src", []string{"'self'"}},
+	{"style-src", []string{"'self'"}},
+	{"font-src", []string{"'self'"}},
+	{"img-src", []string{"'self'", "data:"}},
+	{"connect-src", []string{"'self'"}},
+	{"object-src", []string{"'none'"}},
+	{"base-uri", []string{"'self'"}},
+	{"form-action", []string{"'self'"}},
+	{"frame-ancestors", []string{"'none'"}},
+}
+
+// GenerateHeaders writes a _headers file (Cloudflare Pages and Netlify
+// format) with security headers, a Content-Security-Policy and cache rules.
+// The policy allows the sources of the site's enabled integrations and the
+// sha256 hashes of every inline script and style in the HTML pages of out,
+// so it must run after the pages are rendered.
+func GenerateHeaders(out OutputFS, site website.SiteConfig) error {
+	scripts, styles, err := inlineHashes(out)
+	if err != nil {
+		return fmt.Errorf("hashing inline scripts and styles: %w", err)
+	}
+
+	headers := make(map[string]string, len(defaultSecurityHeaders)+len(site.Headers.Headers))
+	for name, value := range defaultSecurityHeaders {
+		headers[name] = value
+	}
+	for name, value := range site.Headers.Headers {
+		headers[name] = value
+	}
+	headers["Content-Security-Policy"] = contentSecurityPolicy(site, scripts, styles)
+
+	rules, err := cacheRules(out, site)
+	if err != nil {
+		return fmt.Errorf("listing pages for cache rules: %w", err)
+	}
+
+	data, err := headersFile(headers, rules)
+	if err != nil {
+		return err
+	}
+	outPath := "_headers"
+	if err := out.WriteFile(outPath, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	slog.Info("_headers generated", "path", outPath, "script_hashes", len(scripts), "style_hashes", len(styles))
+	return nil
+}
+
+// cacheRules returns headers.cache, or the default rules followed by rules
+// for every fingerprinted asset, the page directories of out and, with
+// self-hosted fonts, /fonts/*. Other static files keep stable names, so they
+// get no rule and are revalidated by the host's default.
+func cacheRules(out fs.FS, site website.SiteConfig) ([]website.CacheRule, error) {
+	if len(site.Headers.Cache) > 0 {
+		return site.Headers.Cache, nil
+	}
+	rules := slices.Clone(defaultCacheRules)
+	assets := make([]string, 0, len(site.AssetURLs))
+	for _, fingerprinted := range site.AssetURLs {
+		assets = append(assets, fingerprinted)
+	}
+	slices.Sort(assets)
+	for _, asset := range assets {
+		rules = append(rules, website.CacheRule{Path: asset, CacheControl: assetCacheControl})
+	}
+	dirs, err := pageDirs(out)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		rules = append(rules, website.CacheRule{Path: dir, CacheControl: pageCacheControl})
+	}
+	if len(website.LocalFonts(site)) > 0 {
+		rules = append(rules, website.CacheRule{Path: "/" + fontsDir + "/*", CacheControl: pageCacheControl})
+	}
+	if len(rules) > maxHeaderRules {
+		slog.Warn("_headers has more cache rules than Cloudflare Pages applies; set headers.cache or fingerprint fewer file types", "rules", len(rules), "limit", maxHeaderRules)
+	}
+	return rules, nil
+}
+
+// pageDirs returns the header paths of the HTML pages in out: "/" for the
+// home page and "/<dir>/*" for each top-level directory with pages. Pages are
+// requested by directory (/blog/hello/), so a rule on "*.html" never matches.
+func pageDirs(out fs.FS) ([]string, error) {
+	var dirs []string
+	err := fs.WalkDir(out, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name == "static" || name == fontsDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(name) != ".html" {
+			return nil
+		}
+		dir, _, nested := strings.Cut(name, "/")
+		switch {
+		case nested:
+			dir = "/" + dir + "/*"
+		case name == "index.html":
+			dir = "/"
+		default:
+			return nil
+		}
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// headersFile renders headers for every path followed by the cache rules.
+// Empty header values are omitted.
+func headersFile(headers map[string]string, rules []website.CacheRule) ([]byte, error) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	var errs []error
+	buf.WriteString("# Generated by the build from config/site.yaml.\n/*\n")
+	for _, name := range names {
+		value := headers[name]
+		if value == "" {
+			continue
+		}
+		if name == "" || strings.ContainsAny(name, ": \r\n") || strings.ContainsAny(value, "\r\n") {
+			errs = append(errs, fmt.Errorf("invalid header %q", name))
+			continue
+		}
+		fmt.Fprintf(&buf, "  %s: %s\n", name, value)
+	}
+	for _, rule := range rules {
+		if !strings.HasPrefix(rule.Path, "/") || strings.ContainsAny(rule.Path, " \r\n") || rule.CacheControl == "" || strings.ContainsAny(rule.CacheControl, "\r\n") {
+			errs = append(errs, fmt.Errorf("invalid cache rule for %q", rule.Path))
+			continue
+		}
+		fmt.Fprintf(&buf, "\n%s\n  Cache-Control: %s\n", rule.Path, rule.CacheControl)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return buf.Bytes(), nil
+}
+
+// contentSecurityPolicy builds the policy from the base directives, the
+// integration sources, the configured extra sources and the inline hashes.
+// Hashes are left out of directives that allow 'unsafe-inline', which
+// browsers ignore once a hash is present.
+func contentSecurityPolicy(site website.SiteConfig, scripts, styles []string) string {
+	sources := make(map[string][]string)
+	var order []string
+	add := func(directive string, values ...string) {
+		if _, ok := sources[directive]; !ok {
+			order = append(order, directive)
+		}
+		for _, v := range values {
+			if !slices.Contains(sources[directive], v) {
+				sources[directive] = append(sources[directive], v)
+			}
+		}
+	}
+
+	for _, d := range cspDirectives {
+		add(d.name, d.sources...)
+	}
+	for _, integration := range website.Integrations(site) {
+		for _, d := range sortedKeys(integration.Sources) {
+			add(d, integration.Sources[d]...)
+		}
+	}
+	for _, d := range sortedKeys(site.Headers.CSP) {
+		add(d, site.Headers.CSP[d]...)
+	}
+	for directive, hashes := range map[string][]string{"script-src": scripts, "style-src": styles} {
+		if slices.Contains(sources[directive], "'unsafe-inline'") {
+			slog.Debug("inline hashes skipped, directive allows unsafe-inline", "directive", directive)
+			continue
+		}
+		add(directive, hashes...)
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, d := range order {
+		parts = append(parts, d+" "+strings.Join(sources[d], " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// executableScriptTypes are the <script> types a CSP applies to. Data blocks
+// such as JSON-LD are not executed and need no hash.
+var executableScriptTypes = map[string]bool{
+	"": true, "text/javascript": true, "application/javascript": true, "module": true,
+}
+
+// inlineHashes returns the sorted CSP hash sources of the inline scripts and
+// styles of every HTML page in out.
+func inlineHashes(out fs.FS) (scripts, styles []string, err error) {
+	seen := make(map[string]bool)
+	err = fs.WalkDir(out, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		page, err := fs.ReadFile(out, name)
+		if err != nil {
+			return err
+		}
+
+		z := html.NewTokenizer(bytes.NewReader(page))
+		var inline string // "script" or "style" when the next text is inline code
+		for {
+			switch z.Next() {
+			case html.ErrorToken:
+				return nil
+			case html.StartTagToken:
+				inline = inlineKind(z)
+			case html.TextToken:
+				if inline == "" {
+					continue
+				}
+				sum := sha256.Sum256(z.Raw())
+				hash := "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+				if !seen[inline+hash] {
+					seen[inline+hash] = true
+					if inline == "script" {
+						scripts = append(scripts, hash)
+					} else {
+						styles = append(styles, hash)
+					}
+				}
+				inline = ""
+			default:
+				inline = ""
+			}
+		}
+	})
+	sort.Strings(scripts)
+	sort.Strings(styles)
+	return scripts, styles, err
+}
+
+// inlineKind reports whether the current start tag opens an inline script
+// or stylesheet the browser executes.
+func inlineKind(z *html.Tokenizer) string {
+	name, hasAttr := z.TagName()
+	tag := string(name)
+	if tag != "script" && tag != "style" {
+		return ""
+	}
+	var typ string
+	for hasAttr {
+		var key, val []byte
+		key, val, hasAttr = z.TagAttr()
+		switch string(key) {
+		case "src":
+			return ""
+		case "type":
+			typ = strings.ToLower(strings.TrimSpace(string(val)))
+		}
+	}
+	if tag == "script" && !executableScriptTypes[typ] {
+		return ""
+	}
+	if tag == "style" && typ != "" && typ != "text/css" {
+		return ""
+	}
+	return tag
+}
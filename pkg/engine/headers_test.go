@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"maciejadamski/pkg/website"
+)
+
+func cspHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// pagesOutput returns a staging output holding the given files.
+func pagesOutput(t *testing.T, files map[string]string) Staging {
+	t.Helper()
+	out, _ := NewMemoryOutput().Begin(false)
+	for name, content := range files {
+		if err := out.WriteFile(name, func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out
+}
+
+func TestInlineHashes(t *testing.T) {
+	out := pagesOutput(t, map[string]string{
+		"index.html": `<script>a()</script><script src="/x.js"></script><script type="application/ld+json">{}</script>` +
+			`<style>p{}</style><style type="text/tailwindcss">@theme{}</style>`,
+		"blog/index.html": `<script>a()</script><script type="module">b()</script>`,
+		"notes.txt":       `<script>ignored()</script>`,
+	})
+
+	scripts, styles, err := inlineHashes(out)
+	if err != nil {
+		t.Fatalf("inlineHashes() error = %v", err)
+	}
+	if len(scripts) != 2 || !strings.Contains(strings.Join(scripts, " "), cspHash("a()")) || !strings.Contains(strings.Join(scripts, " "), cspHash("b()")) {
+		t.Errorf("scripts = %v, want hashes of a() and b()", scripts)
+	}
+	if len(styles) != 1 || styles[0] != cspHash("p{}") {
+		t.Errorf("styles = %v, want hash of p{}", styles)
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	site := website.SiteConfig{
+		GoogleAnalyticsID: "G-TEST",
+		Headers:           website.HeadersConfig{CSP: map[string][]string{"img-src": {"https:"}, "frame-src": {"https://www.youtube.com"}}},
+	}
+	csp := contentSecurityPolicy(site, []string{cspHash("a()")}, []string{cspHash("p{}")})
+
+	directives := map[string]string{}
+	for _, part := range strings.Split(csp, "; ") {
+		name, sources, _ := strings.Cut(part, " ")
+		directives[name] = sources
+	}
+	checks := []struct {
+		directive string
+		contains  string
+		want      bool
+	}{
+		{"script-src", cspHash("a()"), true},
+		{"script-src", "https://www.googletagmanager.com", true},
+		{"script-src", "'unsafe-inline'", false},
+		{"script-src", "'unsafe-eval'", false},
+		// Tailwind's runtime needs inline styles, so hashes would disable it.
+		{"style-src", "'unsafe-inline'", true},
+		{"style-src", cspHash("p{}"), false},
+		{"img-src", "https:", true},
+		{"frame-src", "https://www.youtube.com", true},
+		{"object-src", "'none'", true},
+	}
+	for _, c := range checks {
+		if got := strings.Contains(directives[c.directive], c.contains); got != c.want {
+			t.Errorf("%s contains %s = %v, want %v (policy %q)", c.directive, c.contains, got, c.want, csp)
+		}
+	}
+}
+
+func TestContentSecurityPolicy_Integrations(t *testing.T) {
+	without := contentSecurityPolicy(website.SiteConfig{}, nil, nil)
+	if strings.Contains(without, "googletagmanager") || strings.Contains(without, "'unsafe-eval'") {
+		t.Errorf("policy %q allows disabled integrations", without)
+	}
+	with := contentSecurityPolicy(website.SiteConfig{EnableAlpineJS: true}, nil, nil)
+	if !strings.Contains(with, "'unsafe-eval'") {
+		t.Errorf("policy %q does not allow Alpine", with)
+	}
+}
+
+func TestGenerateHeaders(t *testing.T) {
+	out := pagesOutput(t, map[string]string{"index.html": `<script>a()</script>`})
+	site := website.SiteConfig{Headers: website.HeadersConfig{
+		Enabled: true,
+		Headers: map[string]string{"X-Frame-Options": "", "Strict-Transport-Security": "max-age=63072000"},
+		Cache:   []website.CacheRule{{Path: "/static/*", CacheControl: "public, max-age=60"}},
+	}}
+
+	if err := GenerateHeaders(out, site); err != nil {
+		t.Fatalf("GenerateHeaders() error = %v", err)
+	}
+	data, err := fs.ReadFile(out, "_headers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"/*\n",
+		"  Strict-Transport-Security: max-age=63072000\n",
+		"  X-Content-Type-Options: nosniff\n",
+		"script-src 'self' https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4 https://cdn.jsdelivr.net/npm/@tailwindplus/elements@1 https://cdn.jsdelivr.net/npm/@tailwindplus/elements@1/ " + cspHash("a()"),
+		"\n/static/*\n  Cache-Control: public, max-age=60\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("_headers missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "X-Frame-Options") || strings.Contains(got, "/sitemap.xml") {
+		t.Errorf("_headers has removed header or default cache rules:\n%s", got)
+	}
+}
+
+func TestGenerateHeaders_DefaultCacheRules(t *testing.T) {
+	out := pagesOutput(t, map[string]string{
+		"index.html":               "<p>home</p>",
+		"404.html":                 "<p>missing</p>",
+		"blog/hello/index.html":    "<p>hello</p>",
+		"blog/index.html":          "<p>blog</p>",
+		"pl/blog/witaj/index.html": "<p>witaj</p>",
+		"static/demo/index.html":   "<p>demo</p>",
+		"static/css/site.css":      "body{}",
+	})
+	site := website.SiteConfig{
+		Headers:   website.HeadersConfig{Enabled: true},
+		AssetURLs: map[string]string{"/static/css/site.css": "/static/css/site.0123abcd.css"},
+	}
+
+	rules := func() string {
+		t.Helper()
+		if err := GenerateHeaders(out, site); err != nil {
+			t.Fatalf("GenerateHeaders() error = %v", err)
+		}
+		data, _ := fs.ReadFile(out, "_headers")
+		return string(data)
+	}
+
+	got := rules()
+	for _, want := range []string{
+		"\n/\n  Cache-Control: public, max-age=3600\n",
+		"\n/blog/*\n  Cache-Control: public, max-age=3600\n",
+		"\n/pl/*\n  Cache-Control: public, max-age=3600\n",
+		"\n/static/css/site.0123abcd.css\n  Cache-Control: public, max-age=31536000, immutable\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("_headers missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"*.html", "/fonts/*", "/static/*", "/static/demo", "/404.html"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("_headers has a rule for %q:\n%s", unwanted, got)
+		}
+	}
+
+	site.FontFamily = website.FontFamilies{"inter"}
+	site.Fonts.Families = map[website.FontFamily]website.LocalFont{"inter": {Family: "Inter", Faces: []website.FontFace{{File: "Inter.woff2"}}}}
+	if got := rules(); !strings.Contains(got, "\n/fonts/*\n  Cache-Control: public, max-age=3600\n") {
+		t.Errorf("_headers missing the fonts rule with self-hosted fonts:\n%s", got)
+	}
+}
+
+func TestGenerateHeaders_Invalid(t *testing.T) {
+	out := pagesOutput(t, nil)
+	site := website.SiteConfig{Headers: website.HeadersConfig{
+		Headers: map[string]string{"Bad Name": "x"},
+		Cache:   []website.CacheRule{{Path: "static/*", CacheControl: "no-cache"}},
+	}}
+
+	err := GenerateHeaders(out, site)
+	if err == nil {
+		t.Fatal("GenerateHeaders() expected error, got nil")
+	}
+	for _, want := range []string{"Bad Name", "static/*"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %s", err, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+// languageCodes returns the codes of the given languages.
+func languageCodes(langs []website.LanguageConfig) []string {
+	codes := make([]string, len(langs))
+	for i, l := range langs {
+		codes[i] = l.Code
+	}
+	return codes
+}
+
+// filterLanguage returns only posts written in lang.
+func filterLanguage(posts []markdown.Post, lang string) []markdown.Post {
+	var filtered []markdown.Post
+	for _, p := range posts {
+		if p.Meta.Language == lang {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// checkLanguages reports posts whose language is not one of langs. Such
+// posts would otherwise be left out of every page without notice.
+func checkLanguages(posts []markdown.Post, langs []website.LanguageConfig) error {
+	codes := languageCodes(langs)
+	var errs []error
+	for _, p := range posts {
+		if !slices.Contains(codes, p.Meta.Language) {
+			errs = append(errs, fmt.Errorf("post %q: language %q is not configured (site languages: %v)", p.Meta.Slug, p.Meta.Language, codes))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// groupTranslations groups posts by translation key.
+func groupTranslations(posts []markdown.Post) map[string][]markdown.Post {
+	groups := make(map[string][]markdown.Post)
+	for _, p := range posts {
+		groups[p.Meta.TranslationKey] = append(groups[p.Meta.TranslationKey], p)
+	}
+	return groups
+}
+
+// languagePath prefixes path with the URL prefix of lang.
+func languagePath(site website.SiteConfig, lang, path string) string {
+	return website.LocalizedPath(website.ForLanguage(site, website.LanguageConfig{Code: lang}), path)
+}
+
+// localizedPostPath returns the root-relative URL of a post in its own language.
+func localizedPostPath(site website.SiteConfig, post markdown.Post) string {
+	return languagePath(site, post.Meta.Language, "/blog/"+post.Meta.Slug+"/")
+}
+
+// postAlternates returns hreflang alternates for a post's translation group.
+// Returns nil when the post has no translations.
+func postAlternates(site website.SiteConfig, group []markdown.Post) []website.Alternate {
+	if len(group) < 2 {
+		return nil
+	}
+	defaultLang := defaultLanguage(site)
+	var alternates []website.Alternate
+	var xDefault string
+	for _, p := range group {
+		path := localizedPostPath(site, p)
+		alternates = append(alternates, website.Alternate{Language: p.Meta.Language, Path: path})
+		if p.Meta.Language == defaultLang {
+			xDefault = path
+		}
+	}
+	if xDefault != "" {
+		alternates = append(alternates, website.Alternate{Language: "x-default", Path: xDefault})
+	}
+	return alternates
+}
+
+// blogIndexAlternates returns hreflang alternates for the blog index of every language with posts.
+// Returns nil when only one language has posts. x-default is only added when
+// the default language has posts, since its index is not rendered otherwise.
+func blogIndexAlternates(site website.SiteConfig, posts []markdown.Post) []website.Alternate {
+	defaultLang := defaultLanguage(site)
+	var alternates []website.Alternate
+	var xDefault string
+	for _, lang := range website.Languages(site) {
+		if len(filterLanguage(posts, lang.Code)) == 0 {
+			continue
+		}
+		path := languagePath(site, lang.Code, "/blog/")
+		alternates = append(alternates, website.Alternate{Language: lang.Code, Path: path})
+		if lang.Code == defaultLang {
+			xDefault = path
+		}
+	}
+	if len(alternates) < 2 {
+		return nil
+	}
+	if xDefault != "" {
+		alternates = append(alternates, website.Alternate{Language: "x-default", Path: xDefault})
+	}
+	return alternates
+}
+
+// defaultLanguage returns the unprefixed language of the site.
+func defaultLanguage(site website.SiteConfig) string {
+	if site.DefaultLanguage != "" {
+		return site.DefaultLanguage
+	}
+	return website.GetLanguage(site)
+}
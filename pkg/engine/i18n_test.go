@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+func TestPostAlternates(t *testing.T) {
+	site := website.SiteConfig{
+		Language:  "en",
+		Languages: []website.LanguageConfig{{Code: "pl"}},
+	}
+	group := []markdown.Post{
+		{Meta: markdown.PostMeta{Slug: "hello", Language: "en", TranslationKey: "hello"}},
+		{Meta: markdown.PostMeta{Slug: "czesc", Language: "pl", TranslationKey: "hello"}},
+	}
+
+	got := postAlternates(site, group)
+
+	want := []website.Alternate{
+		{Language: "en", Path: "/blog/hello/"},
+		{Language: "pl", Path: "/pl/blog/czesc/"},
+		{Language: "x-default", Path: "/blog/hello/"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("postAlternates() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("postAlternates()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := postAlternates(site, group[:1]); got != nil {
+		t.Errorf("postAlternates() for untranslated post = %+v, want nil", got)
+	}
+}
+
+func TestBlogIndexAlternates(t *testing.T) {
+	site := website.SiteConfig{
+		Language:  "en",
+		Languages: []website.LanguageConfig{{Code: "pl"}, {Code: "de"}},
+	}
+	post := func(lang string) markdown.Post {
+		return markdown.Post{Meta: markdown.PostMeta{Language: lang}}
+	}
+
+	tests := []struct {
+		name  string
+		posts []markdown.Post
+		want  []website.Alternate
+	}{
+		{
+			name:  "default language with posts",
+			posts: []markdown.Post{post("en"), post("pl")},
+			want: []website.Alternate{
+				{Language: "en", Path: "/blog/"},
+				{Language: "pl", Path: "/pl/blog/"},
+				{Language: "x-default", Path: "/blog/"},
+			},
+		},
+		{
+			name:  "default language without posts",
+			posts: []markdown.Post{post("pl"), post("de")},
+			want: []website.Alternate{
+				{Language: "pl", Path: "/pl/blog/"},
+				{Language: "de", Path: "/de/blog/"},
+			},
+		},
+		{
+			name:  "one language",
+			posts: []markdown.Post{post("pl")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := blogIndexAlternates(site, tt.posts)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("blogIndexAlternates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild_UnknownPostLanguage(t *testing.T) {
+	opts, _ := memoryTestOptions()
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md": {Data: []byte("---\ntitle: Hello\npublished: true\n---\nHi\n")},
+		"blog/hallo.md": {Data: []byte("---\ntitle: Hallo\nlanguage: de\npublished: true\n---\nHi\n")},
+		"blog/draft.md": {Data: []byte("---\ntitle: Draft\nlanguage: fr\n---\nHi\n")},
+	}
+
+	err := Build(context.Background(), ComponentRegistry{}, opts)
+	if err == nil || !strings.Contains(err.Error(), `post "hallo": language "de"`) {
+		t.Fatalf("Build() error = %v, want the post with an unknown language", err)
+	}
+	if strings.Contains(err.Error(), "draft") {
+		t.Errorf("Build() error = %v, want unpublished posts ignored", err)
+	}
+}
@@ -0,0 +1,388 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"maciejadamski/pkg/website"
+
+	"golang.org/x/net/html"
+)
+
+// BrokenLink is a reference in a generated page that does not resolve.
+type BrokenLink struct {
+	// Page is the output file containing the link, e.g. "blog/index.html".
+	Page string
+	// URL is the link as written in the page.
+	URL    string
+	Reason string
+}
+
+// BrokenLinksError is returned by CheckLinks when links do not resolve.
+// Its message is the report, one link per line.
+type BrokenLinksError struct {
+	Links []BrokenLink
+}
+
+func (e *BrokenLinksError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d broken link(s):", len(e.Links))
+	for _, l := range e.Links {
+		fmt.Fprintf(&b, "\n  %s: %s (%s)", l.Page, l.URL, l.Reason)
+	}
+	return b.String()
+}
+
+// linkAttrs lists the URL attributes checked per element.
+var linkAttrs = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"link":   {"href"},
+	"img":    {"src", "srcset"},
+	"source": {"src", "srcset"},
+	"script": {"src"},
+	"iframe": {"src"},
+	"audio":  {"src"},
+	"video":  {"src", "poster"},
+	"track":  {"src"},
+	"embed":  {"src"},
+}
+
+// linkMetaProperties are the <meta> tags whose content is a URL.
+var linkMetaProperties = map[string]bool{
+	"og:url": true, "og:image": true, "twitter:image": true, "twitter:url": true,
+}
+
+// skippedLinkRels are <link> relations pointing at origins, not documents.
+var skippedLinkRels = map[string]bool{"preconnect": true, "dns-prefetch": true}
+
+// defaultLinkTimeout bounds each external request when no client is given.
+const defaultLinkTimeout = 10 * time.Second
+
+// externalLinkWorkers is the number of concurrent external requests.
+const externalLinkWorkers = 8
+
+// pageLink is a URL found in a page.
+type pageLink struct {
+	page string
+	raw  string
+	url  *url.URL
+}
+
+// CheckLinks parses every HTML page in out and verifies that internal links,
+// #fragment anchors, image and script sources and the canonical and Open
+// Graph URLs resolve to files in out. URLs under site.URL count as internal.
+// With site.Links.External, off-site http(s) URLs are requested with client
+// (a default client when nil) and fail on errors and 4xx/5xx responses.
+// Broken links are returned as a *BrokenLinksError.
+func CheckLinks(ctx context.Context, out fs.FS, site website.SiteConfig, client *http.Client) error {
+	base, err := url.Parse(strings.TrimRight(site.URL, "/") + "/")
+	if err != nil {
+		return fmt.Errorf("parsing site url: %w", err)
+	}
+
+	var links []pageLink
+	err = fs.WalkDir(out, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		if err := checkCanceled(ctx, "checking links"); err != nil {
+			return err
+		}
+		page, err := fs.ReadFile(out, name)
+		if err != nil {
+			return err
+		}
+		pageURL := base.ResolveReference(&url.URL{Path: website.OutputURLPath(name)})
+		for _, raw := range pageURLs(page) {
+			u, err := url.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				links = append(links, pageLink{page: name, raw: raw})
+				continue
+			}
+			links = append(links, pageLink{page: name, raw: raw, url: pageURL.ResolveReference(u)})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	checker := &linkChecker{out: out, base: base, ids: make(map[string]map[string]bool)}
+	var broken []BrokenLink
+	external := make(map[string][]pageLink)
+	seen := make(map[string]bool)
+	for _, l := range links {
+		key := l.page + "\x00" + l.raw
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if l.url == nil {
+			broken = append(broken, BrokenLink{Page: l.page, URL: l.raw, Reason: "invalid URL"})
+			continue
+		}
+		if l.url.Scheme != "http" && l.url.Scheme != "https" {
+			continue // mailto:, tel:, data:, ...
+		}
+		if ignoredLink(site.Links.Ignore, l) {
+			continue
+		}
+		if l.url.Host != base.Host {
+			if site.Links.External {
+				u := *l.url
+				u.Fragment = ""
+				external[u.String()] = append(external[u.String()], l)
+			}
+			continue
+		}
+		if reason, err := checker.internal(l.url); err != nil {
+			return err
+		} else if reason != "" {
+			broken = append(broken, BrokenLink{Page: l.page, URL: l.raw, Reason: reason})
+		}
+	}
+
+	if len(external) > 0 {
+		if client == nil {
+			client = &http.Client{Timeout: defaultLinkTimeout}
+		}
+		for target, reason := range checkExternalLinks(ctx, client, external) {
+			for _, l := range external[target] {
+				broken = append(broken, BrokenLink{Page: l.page, URL: l.raw, Reason: reason})
+			}
+		}
+		if err := checkCanceled(ctx, "checking links"); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("links checked", "links", len(seen), "external", len(external), "broken", len(broken))
+	if len(broken) == 0 {
+		return nil
+	}
+	sort.Slice(broken, func(i, j int) bool {
+		if broken[i].Page != broken[j].Page {
+			return broken[i].Page < broken[j].Page
+		}
+		return broken[i].URL < broken[j].URL
+	})
+	return &BrokenLinksError{Links: broken}
+}
+
+// ignoredLink reports whether the link starts with one of the ignored
+// prefixes, as written or resolved.
+func ignoredLink(prefixes []string, l pageLink) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(l.raw, prefix) || strings.HasPrefix(l.url.String(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pageURLs returns the URLs referenced by a page's elements.
+func pageURLs(page []byte) []string {
+	var urls []string
+	z := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return urls
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		tag := string(name)
+		attrs := make(map[string]string)
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			attrs[string(key)] = string(val)
+		}
+
+		if tag == "meta" {
+			prop := attrs["property"]
+			if prop == "" {
+				prop = attrs["name"]
+			}
+			if linkMetaProperties[prop] && attrs["content"] != "" {
+				urls = append(urls, attrs["content"])
+			}
+			continue
+		}
+		if tag == "link" && skippedLinkRels[strings.ToLower(attrs["rel"])] {
+			continue
+		}
+		for _, attr := range linkAttrs[tag] {
+			val, ok := attrs[attr]
+			if !ok {
+				continue
+			}
+			if attr == "srcset" {
+				urls = append(urls, srcsetURLs(val)...)
+				continue
+			}
+			urls = append(urls, val)
+		}
+	}
+}
+
+// srcsetURLs returns the image URLs of a srcset attribute.
+func srcsetURLs(srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+// linkChecker resolves internal URLs against the output.
+type linkChecker struct {
+	out  fs.FS
+	base *url.URL
+	// ids caches the anchor targets of each parsed page.
+	ids map[string]map[string]bool
+}
+
+// internal returns why u does not resolve, or "" when it does.
+func (c *linkChecker) internal(u *url.URL) (string, error) {
+	name, ok := c.file(u.Path)
+	if !ok {
+		return "not found", nil
+	}
+	if u.Fragment == "" || u.Fragment == "top" || path.Ext(name) != ".html" {
+		return "", nil
+	}
+	ids, err := c.anchors(name)
+	if err != nil {
+		return "", err
+	}
+	if !ids[u.Fragment] {
+		return fmt.Sprintf("no anchor #%s in %s", u.Fragment, name), nil
+	}
+	return "", nil
+}
+
+// file returns the output file served at urlPath, trying the index.html and
+// .html forms static hosts resolve.
+func (c *linkChecker) file(urlPath string) (string, bool) {
+	p := strings.TrimPrefix(urlPath, strings.TrimSuffix(c.base.Path, "/"))
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	candidates := []string{p, path.Join(p, "index.html"), p + ".html"}
+	if p == "" || strings.HasSuffix(urlPath, "/") {
+		candidates = []string{path.Join(p, "index.html")}
+	}
+	for _, name := range candidates {
+		if info, err := fs.Stat(c.out, name); err == nil && !info.IsDir() {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// anchors returns the id and a[name] values of a page.
+func (c *linkChecker) anchors(name string) (map[string]bool, error) {
+	if ids, ok := c.ids[name]; ok {
+		return ids, nil
+	}
+	page, err := fs.ReadFile(c.out, name)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	z := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tag, hasAttr := z.TagName()
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			if string(key) == "id" || (string(key) == "name" && string(tag) == "a") {
+				ids[string(val)] = true
+			}
+		}
+	}
+	c.ids[name] = ids
+	return ids, nil
+}
+
+// checkExternalLinks requests every target and returns the reasons of the
+// ones that failed.
+func checkExternalLinks(ctx context.Context, client *http.Client, targets map[string][]pageLink) map[string]string {
+	jobs := make(chan string)
+	var mu sync.Mutex
+	failed := make(map[string]string)
+	var wg sync.WaitGroup
+	for range min(externalLinkWorkers, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				if reason := checkExternalLink(ctx, client, target); reason != "" {
+					mu.Lock()
+					failed[target] = reason
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- target
+	}
+	close(jobs)
+	wg.Wait()
+	return failed
+}
+
+// checkExternalLink returns why target failed, or "" when it responded
+// successfully. Servers that reject HEAD are retried with GET.
+func checkExternalLink(ctx context.Context, client *http.Client, target string) string {
+	status, err := requestStatus(ctx, client, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+		status, err = requestStatus(ctx, client, http.MethodGet, target)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if status >= 400 {
+		return fmt.Sprintf("HTTP %d", status)
+	}
+	return ""
+}
+
+func requestStatus(ctx context.Context, client *http.Client, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/website"
+)
+
+func brokenLinks(t *testing.T, err error) []BrokenLink {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var broken *BrokenLinksError
+	if !errors.As(err, &broken) {
+		t.Fatalf("CheckLinks() error = %v, want *BrokenLinksError", err)
+	}
+	return broken.Links
+}
+
+func TestCheckLinks(t *testing.T) {
+	out := fstest.MapFS{
+		"index.html": {Data: []byte(`<html><head>
+<link rel="canonical" href="https://example.com/">
+<link rel="preconnect" href="https://fonts.gstatic.com">
+<meta property="og:url" content="https://example.com/">
+<meta property="og:image" content="https://example.com/static/missing.png">
+</head><body>
+<a href="/blog/">Blog</a> <a href="blog/post/#intro">Intro</a> <a href="#contact">Contact</a>
+<a href="/about">About</a> <a href="/blog/gone/">Gone</a> <a href="/#nowhere">Nowhere</a>
+<a href="mailto:me@example.com">Mail</a> <a href="https://other.example/">Other</a>
+<img src="/static/a.png" srcset="/static/a.png 1x, /static/b.png 2x">
+<section id="contact"></section>
+</body></html>`)},
+		"about.html":           {Data: []byte(`<p>About</p>`)},
+		"blog/index.html":      {Data: []byte(`<a href="post/">Post</a> <a href="../index.html#top">Top</a>`)},
+		"blog/post/index.html": {Data: []byte(`<h2 id="intro">Intro</h2><a href="../../static/a.png?v=1">Image</a>`)},
+		"static/a.png":         {Data: []byte("png")},
+	}
+	site := website.SiteConfig{URL: "https://example.com"}
+
+	got := brokenLinks(t, CheckLinks(context.Background(), out, site, nil))
+	want := []BrokenLink{
+		{Page: "index.html", URL: "/#nowhere", Reason: "no anchor #nowhere in index.html"},
+		{Page: "index.html", URL: "/blog/gone/", Reason: "not found"},
+		{Page: "index.html", URL: "/static/b.png", Reason: "not found"},
+		{Page: "index.html", URL: "https://example.com/static/missing.png", Reason: "not found"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckLinks() broken = %+v\nwant %+v", got, want)
+	}
+
+	site.Links.Ignore = []string{"/blog/gone/", "https://example.com/static/"}
+	got = brokenLinks(t, CheckLinks(context.Background(), out, site, nil))
+	if len(got) != 1 || got[0].URL != "/#nowhere" {
+		t.Errorf("CheckLinks() with ignore broken = %+v, want only /#nowhere", got)
+	}
+}
+
+func TestCheckLinks_External(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/ok":
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	out := fstest.MapFS{
+		"index.html": {Data: []byte(`<a href="` + srv.URL + `/ok#section">OK</a>
+<a href="` + srv.URL + `/no-head">No HEAD</a>
+<a href="` + srv.URL + `/missing">Missing</a>`)},
+		"other.html": {Data: []byte(`<a href="` + srv.URL + `/missing">Missing</a>`)},
+	}
+	site := website.SiteConfig{URL: "https://example.com", Links: website.LinksConfig{External: true}}
+
+	got := brokenLinks(t, CheckLinks(context.Background(), out, site, srv.Client()))
+	want := []BrokenLink{
+		{Page: "index.html", URL: srv.URL + "/missing", Reason: "HTTP 404"},
+		{Page: "other.html", URL: srv.URL + "/missing", Reason: "HTTP 404"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckLinks() broken = %+v\nwant %+v", got, want)
+	}
+	if len(requests) != 4 {
+		t.Errorf("requests = %v, want each URL once and a GET retry for /no-head", requests)
+	}
+
+	site.Links.External = false
+	requests = nil
+	if err := CheckLinks(context.Background(), out, site, srv.Client()); err != nil || len(requests) != 0 {
+		t.Errorf("CheckLinks() without external = %v, %d requests; want nil, 0", err, len(requests))
+	}
+}
+
+func TestBuild_BrokenLinks(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\nlinks:\n  check: true\n")},
+	}
+	opts.PublicFS = fstest.MapFS{
+		"404.html": {Data: []byte(`<a href="/missing/">Home</a>`)},
+	}
+
+	err := Build(context.Background(), ComponentRegistry{}, opts)
+	if links := brokenLinks(t, err); len(links) != 1 || links[0].Page != "404.html" {
+		t.Fatalf("Build() broken links = %+v, want /missing/ in 404.html", links)
+	}
+	if !strings.Contains(err.Error(), "404.html: /missing/ (not found)") {
+		t.Errorf("report = %q, want it to list the link", err.Error())
+	}
+	if _, err := fs.Stat(out, "404.html"); err == nil {
+		t.Error("output published despite broken links")
+	}
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+// LLMPost is a post listed in llms.txt. Path is the URL path of its HTML
+// page; the markdown mirror is served at Path + "index.md".
+type LLMPost struct {
+	Path string
+	Post markdown.Post
+}
+
+// GenerateLLMs writes llms.txt, a site summary linking the markdown mirror
+// of every post, and llms-full.txt, the full text of every post in one file.
+func GenerateLLMs(out OutputFS, site website.SiteConfig, posts []LLMPost) error {
+	var index bytes.Buffer
+	writeLLMsHeader(&index, site)
+
+	var sections [][]LLMPost
+	var names []string
+	for _, lang := range website.Languages(site) {
+		var section []LLMPost
+		for _, p := range posts {
+			if p.Post.Meta.Language == lang.Code {
+				section = append(section, p)
+			}
+		}
+		if len(section) > 0 {
+			sections = append(sections, section)
+			names = append(names, languageName(lang))
+		}
+	}
+
+	for i, section := range sections {
+		heading := "Blog"
+		if len(sections) > 1 {
+			heading = fmt.Sprintf("Blog (%s)", names[i])
+		}
+		fmt.Fprintf(&index, "## %s\n\n", heading)
+		for _, p := range section {
+			fmt.Fprintf(&index, "- [%s](%s)", p.Post.Meta.Title, website.AbsoluteURL(site, p.Path+"index.md"))
+			if p.Post.Meta.Description != "" {
+				fmt.Fprintf(&index, ": %s", p.Post.Meta.Description)
+			}
+			index.WriteString("\n")
+		}
+		index.WriteString("\n")
+	}
+	fmt.Fprintf(&index, "## Optional\n\n- [Full text](%s): every post in a single file\n", website.AbsoluteURL(site, "/llms-full.txt"))
+
+	var full bytes.Buffer
+	writeLLMsHeader(&full, site)
+	for i, p := range posts {
+		if i > 0 {
+			full.WriteString("\n---\n\n")
+		}
+		full.Write(PostMarkdown(site, p.Path, p.Post))
+	}
+
+	for name, data := range map[string][]byte{"llms.txt": index.Bytes(), "llms-full.txt": full.Bytes()} {
+		if err := out.WriteFile(name, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("llms.txt generated", "posts", len(posts))
+	return nil
+}
+
+func writeLLMsHeader(buf *bytes.Buffer, site website.SiteConfig) {
+	fmt.Fprintf(buf, "# %s\n\n", site.Name)
+	if site.Description != "" {
+		fmt.Fprintf(buf, "> %s\n\n", site.Description)
+	}
+}
+
+// languageName returns the configured display name of lang, or its code.
+func languageName(lang website.LanguageConfig) string {
+	if lang.Name != "" {
+		return lang.Name
+	}
+	return lang.Code
+}
+
+// PostMarkdown returns the markdown mirror of a post served at urlPath: its
+// title, description and metadata followed by the markdown source of the body.
+func PostMarkdown(site website.SiteConfig, urlPath string, post markdown.Post) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", post.Meta.Title)
+	if post.Meta.Description != "" {
+		fmt.Fprintf(&buf, "> %s\n\n", post.Meta.Description)
+	}
+
+	if !post.Meta.Time.IsZero() {
+		fmt.Fprintf(&buf, "- Published: %s\n", post.Meta.Time.Format("2006-01-02"))
+	}
+	if modified := postLastMod(site, post); modified.After(post.Meta.Time) {
+		fmt.Fprintf(&buf, "- Updated: %s\n", modified.Format("2006-01-02"))
+	}
+	if author := postAuthorName(site, post); author != "" {
+		fmt.Fprintf(&buf, "- Author: %s\n", author)
+	}
+	fmt.Fprintf(&buf, "- URL: %s\n\n", website.AbsoluteURL(site, urlPath))
+
+	buf.WriteString(strings.TrimSpace(post.Raw))
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// postAuthorName returns the display name of the post's author.
+func postAuthorName(site website.SiteConfig, post markdown.Post) string {
+	if author, ok := website.ResolveAuthor(site, post.Meta.Author); ok {
+		return author.Name
+	}
+	return post.Meta.Author
+}
+
+// llmsPosts returns the indexable article pages of the build.
+func llmsPosts(pages []renderedPage) []LLMPost {
+	var posts []LLMPost
+	for _, page := range pages {
+		if !page.Inputs.SEO.IsArticle || page.Inputs.SEO.NoIndex || len(page.Inputs.Posts) != 1 {
+			continue
+		}
+		posts = append(posts, LLMPost{Path: page.Path, Post: page.Inputs.Posts[0]})
+	}
+	return posts
+}
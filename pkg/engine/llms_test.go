@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+func TestGenerateLLMs(t *testing.T) {
+	site := website.SiteConfig{
+		Name:        "Test",
+		URL:         "https://example.com",
+		Description: "A test site",
+		Languages:   []website.LanguageConfig{{Code: "pl", Name: "Polski"}},
+	}
+	posts := []LLMPost{
+		{Path: "/blog/hello/", Post: markdown.Post{
+			Meta: markdown.PostMeta{Title: "Hello", Description: "First post", Language: "en"},
+			Raw:  "Hi there.\n",
+		}},
+		{Path: "/pl/blog/hello/", Post: markdown.Post{
+			Meta: markdown.PostMeta{Title: "Cześć", Language: "pl"},
+			Raw:  "Cześć.\n",
+		}},
+	}
+
+	out, _ := NewMemoryOutput().Begin(false)
+	if err := GenerateLLMs(out, site, posts); err != nil {
+		t.Fatalf("GenerateLLMs() error = %v", err)
+	}
+
+	index, err := fs.ReadFile(out, "llms.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Test\n\n> A test site\n\n" +
+		"## Blog (en)\n\n- [Hello](https://example.com/blog/hello/index.md): First post\n\n" +
+		"## Blog (Polski)\n\n- [Cześć](https://example.com/pl/blog/hello/index.md)\n\n" +
+		"## Optional\n\n- [Full text](https://example.com/llms-full.txt): every post in a single file\n"
+	if string(index) != want {
+		t.Errorf("llms.txt = %q, want %q", index, want)
+	}
+
+	full, err := fs.ReadFile(out, "llms-full.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"# Test\n", "# Hello\n", "Hi there.\n", "\n---\n\n# Cześć\n", "Cześć.\n"} {
+		if !strings.Contains(string(full), s) {
+			t.Errorf("llms-full.txt missing %q:\n%s", s, full)
+		}
+	}
+}
+
+func TestPostMarkdown(t *testing.T) {
+	site := website.SiteConfig{
+		URL:     "https://example.com",
+		Authors: map[string]website.Author{"jane": {ID: "jane", Name: "Jane Doe"}},
+	}
+	post := markdown.Post{
+		Meta: markdown.PostMeta{
+			Title:       "Hello",
+			Description: "First post",
+			Author:      "jane",
+			Time:        time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+			Extra:       map[string]any{"updated": "2026-02-01"},
+		},
+		Raw: "\n## Intro\n\nSome *text*.\n\n",
+	}
+
+	got := string(PostMarkdown(site, "/blog/hello/", post))
+	want := "# Hello\n\n> First post\n\n" +
+		"- Published: 2026-01-15\n- Updated: 2026-02-01\n- Author: Jane Doe\n- URL: https://example.com/blog/hello/\n\n" +
+		"## Intro\n\nSome *text*.\n"
+	if got != want {
+		t.Errorf("PostMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestBuild_MarkdownMirrors(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md":  {Data: []byte("---\ntitle: Hello\npublished: true\n---\nHi *there*.\n")},
+		"blog/hidden.md": {Data: []byte("---\ntitle: Hidden\npublished: true\nnoindex: true\n---\nSecret.\n")},
+	}
+	components := ComponentRegistry{
+		BlogPost: func(_ website.SiteConfig, _ website.SEO, p markdown.Post) templ.Component {
+			return mockComponent{content: p.Content}
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	mirror, err := fs.ReadFile(out, "blog/hello/index.md")
+	if err != nil {
+		t.Fatalf("markdown mirror not written: %v", err)
+	}
+	if !strings.HasSuffix(string(mirror), "\nHi *there*.\n") {
+		t.Errorf("mirror = %q, want raw markdown body", mirror)
+	}
+	if _, err := fs.Stat(out, "blog/hidden/index.md"); err == nil {
+		t.Error("noindex post has a markdown mirror")
+	}
+
+	index, err := fs.ReadFile(out, "llms.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "/blog/hello/index.md") || strings.Contains(string(index), "Hidden") {
+		t.Errorf("llms.txt = %q, want only indexable posts", index)
+	}
+}
@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryOutput keeps builds in memory. It implements fs.FS over the last
+// committed build, so it can be read by tests or served with
+// http.FileServerFS. It is safe to read while a new build is staged.
+type MemoryOutput struct {
+	mu    sync.RWMutex
+	files memFS
+}
+
+// NewMemoryOutput returns an empty in-memory output.
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{files: memFS{}}
+}
+
+// Begin starts a staged build, copying the current files when incremental is set.
+func (m *MemoryOutput) Begin(incremental bool) (Staging, error) {
+	files := memFS{}
+	if incremental {
+		files = m.snapshot().clone()
+	}
+	return &memoryStaging{files: files, commit: m.replace}, nil
+}
+
+// Open opens a file of the last committed build.
+func (m *MemoryOutput) Open(name string) (fs.File, error) {
+	return m.snapshot().Open(name)
+}
+
+func (m *MemoryOutput) snapshot() memFS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files
+}
+
+func (m *MemoryOutput) replace(files memFS) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files = files
+	return nil
+}
+
+// memoryStaging collects a build in memory and hands it to commit.
+type memoryStaging struct {
+	files  memFS
+	commit func(memFS) error
+}
+
+func (s *memoryStaging) Open(name string) (fs.File, error) {
+	return s.files.Open(name)
+}
+
+func (s *memoryStaging) WriteFile(name string, write func(w io.Writer) error) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	s.files[name] = buf.Bytes()
+	return nil
+}
+
+func (s *memoryStaging) Remove(name string) error {
+	if _, ok := s.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(s.files, name)
+	return nil
+}
+
+func (s *memoryStaging) Commit() error {
+	files := s.files
+	s.files = memFS{}
+	return s.commit(files)
+}
+
+func (s *memoryStaging) Discard() error {
+	s.files = memFS{}
+	return nil
+}
+
+// memFS is a read-only fs.FS over a map of file names to contents.
+// Directories are implied by the file names.
+type memFS map[string][]byte
+
+func (m memFS) clone() memFS {
+	return maps.Clone(m)
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := m[name]; ok {
+		return &memFile{info: memInfo{name: path.Base(name), size: int64(len(data))}, Reader: bytes.NewReader(data)}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := map[string]memInfo{}
+	for file, data := range m {
+		rest, ok := strings.CutPrefix(file, prefix)
+		if !ok {
+			continue
+		}
+		if child, _, isDir := strings.Cut(rest, "/"); isDir {
+			children[child] = memInfo{name: child, dir: true}
+		} else {
+			children[child] = memInfo{name: child, size: int64(len(data))}
+		}
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, info := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return &memDir{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// memInfo describes a file or directory in a memFS.
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// memFile is an open memFS file. It supports seeking so it can be served over HTTP.
+type memFile struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an open memFS directory.
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
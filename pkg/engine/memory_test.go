@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+// memoryTestOptions returns options for a build with in-memory inputs and output.
+func memoryTestOptions() (BuildOptions, *MemoryOutput) {
+	out := NewMemoryOutput()
+	return BuildOptions{
+		ConfigFS: fstest.MapFS{
+			"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\n")},
+		},
+		ContentFS: fstest.MapFS{},
+		StaticFS:  fstest.MapFS{},
+		Output:    out,
+	}, out
+}
+
+func TestBuild_InMemory(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md": {Data: []byte("---\ntitle: Hello\npublished: true\n---\nHi\n")},
+	}
+	opts.StaticFS = fstest.MapFS{
+		"css/site.css": {Data: []byte("body {}")},
+	}
+
+	components := ComponentRegistry{
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return mockComponent{content: "home"}
+		},
+		BlogPost: func(_ website.SiteConfig, _ website.SEO, p markdown.Post) templ.Component {
+			return mockComponent{content: p.Content}
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if err := fstest.TestFS(out, "index.html", "blog/hello/index.html", "static/css/site.css"); err != nil {
+		t.Errorf("output fs: %v", err)
+	}
+	got, err := fs.ReadFile(out, "blog/hello/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "<p>Hi</p>\n" {
+		t.Errorf("post = %q, want rendered markdown", got)
+	}
+
+	// The committed build can be served directly.
+	rec := httptest.NewRecorder()
+	http.FileServerFS(out).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/blog/hello/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "<p>Hi</p>\n" {
+		t.Errorf("GET /blog/hello/ = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMemoryOutput_Staging(t *testing.T) {
+	out := NewMemoryOutput()
+	write := func(s Staging, name, content string) {
+		t.Helper()
+		if err := s.WriteFile(name, func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, _ := out.Begin(false)
+	write(first, "a.html", "a")
+	if _, err := fs.Stat(out, "a.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("staged file visible before commit")
+	}
+	if err := first.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	second, _ := out.Begin(true)
+	if _, err := fs.Stat(second, "a.html"); err != nil {
+		t.Errorf("incremental staging missing previous file: %v", err)
+	}
+	write(second, "b.html", "b")
+	second.Discard()
+
+	if _, err := fs.Stat(out, "b.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("discarded file visible")
+	}
+	if got, _ := fs.ReadFile(out, "a.html"); string(got) != "a" {
+		t.Errorf("a.html = %q, want a", got)
+	}
+}
@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"slices"
+
+	"maciejadamski/pkg/generator"
+	"maciejadamski/pkg/website"
+)
+
+// newMinifier returns a minifier when the site config enables minification
+// for the build environment, or nil.
+func newMinifier(site website.SiteConfig) *generator.Minifier {
+	if !site.Minify.Enabled {
+		return nil
+	}
+	envs := site.Minify.Environments
+	if len(envs) == 0 {
+		envs = []string{website.EnvProduction}
+	}
+	env := site.Environment
+	if env == "" {
+		env = website.EnvProduction
+	}
+	if !slices.Contains(envs, env) {
+		return nil
+	}
+	return generator.NewMinifier()
+}
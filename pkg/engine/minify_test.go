@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+func TestNewMinifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		minify website.MinifyConfig
+		env    string
+		want   bool
+	}{
+		{"disabled", website.MinifyConfig{}, "", false},
+		{"production by default", website.MinifyConfig{Enabled: true}, "", true},
+		{"development by default", website.MinifyConfig{Enabled: true}, "development", false},
+		{"listed environment", website.MinifyConfig{Enabled: true, Environments: []string{"staging"}}, "staging", true},
+		{"unlisted production", website.MinifyConfig{Enabled: true, Environments: []string{"staging"}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := website.SiteConfig{Minify: tt.minify, Environment: tt.env}
+			if got := newMinifier(site) != nil; got != tt.want {
+				t.Errorf("newMinifier() enabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild_Minify(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\nminify:\n  enabled: true\n")},
+	}
+	opts.StaticFS = fstest.MapFS{
+		"css/site.css": {Data: []byte("body {\n  margin: 0;\n}\n")},
+	}
+	components := ComponentRegistry{
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return mockComponent{content: "<div>\n  <p>  home  </p>\n</div>\n"}
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"index.html":          "<div><p>home</p></div>",
+		"static/css/site.css": "body{margin:0}",
+	} {
+		got, err := fs.ReadFile(out, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"net/url"
+	"strconv"
+
+	"maciejadamski/pkg/generator"
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// ogImageRenderer renders the Open Graph images of posts.
+type ogImageRenderer struct {
+	style generator.OGStyle
+	// fontsHash identifies the font files, which are not part of the config
+	// hash, so changing a font re-renders the images.
+	fontsHash string
+}
+
+// newOGImageRenderer returns a renderer styled with the theme colors and the
+// configured fonts, or nil when site.OGImages is disabled.
+func newOGImageRenderer(site website.SiteConfig, fonts fs.FS) (*ogImageRenderer, error) {
+	cfg := site.OGImages
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	titleData, err := loadFont(fonts, cfg.TitleFont, gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("og_images title_font: %w", err)
+	}
+	textData, err := loadFont(fonts, cfg.TextFont, goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("og_images text_font: %w", err)
+	}
+	titleFont, err := generator.ParseFont(titleData)
+	if err != nil {
+		return nil, fmt.Errorf("og_images title_font %s: %w", cfg.TitleFont, err)
+	}
+	textFont, err := generator.ParseFont(textData)
+	if err != nil {
+		return nil, fmt.Errorf("og_images text_font %s: %w", cfg.TextFont, err)
+	}
+
+	style, err := ogStyle(site.Theme, titleFont, textFont)
+	if err != nil {
+		return nil, err
+	}
+	return &ogImageRenderer{style: style, fontsHash: hashBytes(append(titleData, textData...))}, nil
+}
+
+// loadFont reads name from fonts, or returns fallback when name is empty.
+func loadFont(fonts fs.FS, name string, fallback []byte) ([]byte, error) {
+	if name == "" {
+		return fallback, nil
+	}
+	if fonts == nil {
+		return nil, fmt.Errorf("%s: no fonts directory", name)
+	}
+	return fs.ReadFile(fonts, name)
+}
+
+// ogStyle maps the theme colors onto the image: the page background, the
+// brand color as accent, heading color for the title and body, muted and
+// border colors for the footer.
+func ogStyle(theme website.ThemeConfig, titleFont, textFont *opentype.Font) (generator.OGStyle, error) {
+	style := generator.OGStyle{TitleFont: titleFont, TextFont: textFont}
+	for _, c := range []struct {
+		name  string
+		value string
+		dst   *color.Color
+	}{
+		{"color_page_background", theme.ColorPageBackground, &style.Background},
+		{"color_brand", theme.ColorBrand, &style.Accent},
+		{"color_text_heading", theme.ColorTextHeading, &style.Title},
+		{"color_text_body", theme.ColorTextBody, &style.Text},
+		{"color_text_muted", theme.ColorTextMuted, &style.Muted},
+		{"color_border", theme.ColorBorder, &style.Border},
+	} {
+		rgba, err := generator.ParseHexColor(c.value)
+		if err != nil {
+			return style, fmt.Errorf("og_images: theme %s: %w", c.name, err)
+		}
+		*c.dst = rgba
+	}
+	return style, nil
+}
+
+// write renders the image of post to name and points seo at it. The image
+// alt text defaults to the post title.
+func (r *ogImageRenderer) write(out OutputFS, cache *buildCache, site website.SiteConfig, post markdown.Post, seo *website.SEO, name string) error {
+	card := generator.OGCard{
+		Title:  post.Meta.Title,
+		Date:   website.FormatDate(site, post.Meta.Time),
+		Author: post.Meta.Author,
+		Site:   site.Name,
+	}
+	if author, ok := website.ResolveAuthor(site, post.Meta.Author); ok {
+		card.Author = author.Name
+	}
+	if u, err := url.Parse(site.URL); err == nil {
+		card.Host = u.Host
+	}
+
+	key, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	err = cache.generate(out, name, append(key, r.fontsHash...), func() ([]byte, error) {
+		return generator.RenderOGImage(card, r.style)
+	})
+	if err != nil {
+		return err
+	}
+
+	seo.OGImage = "/" + name
+	seo.OGImageWidth = strconv.Itoa(generator.OGImageWidth)
+	seo.OGImageHeight = strconv.Itoa(generator.OGImageHeight)
+	if seo.OGImageAlt == "" {
+		seo.OGImageAlt = post.Meta.Title
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// ogComponents render the OG image fields of each post's SEO.
+func ogComponents() ComponentRegistry {
+	return ComponentRegistry{
+		BlogPost: func(_ website.SiteConfig, seo website.SEO, _ markdown.Post) templ.Component {
+			return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+				_, err := io.WriteString(w, seo.OGImage+" "+seo.OGImageWidth+"x"+seo.OGImageHeight+" "+seo.OGImageAlt)
+				return err
+			})
+		},
+	}
+}
+
+func TestBuild_OGImages(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml":  {Data: []byte("name: Test\nurl: https://example.com\nog_images:\n  enabled: true\n  text_font: mono.ttf\n")},
+		"theme.yaml": {Data: []byte("color_page_background: \"#ffffff\"\ncolor_brand: \"#52008d\"\n")},
+	}
+	opts.FontsFS = fstest.MapFS{"mono.ttf": {Data: gomono.TTF}}
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md":  {Data: []byte("---\ntitle: Hello\ndate: 2026-01-02\npublished: true\n---\nHi\n")},
+		"blog/custom.md": {Data: []byte("---\ntitle: Custom\npublished: true\nimage: /static/custom.png\n---\nHi\n")},
+	}
+
+	if err := Build(context.Background(), ogComponents(), opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	page, _ := fs.ReadFile(out, "blog/hello/index.html")
+	if want := "/blog/hello/og.png 1200x630 Hello"; string(page) != want {
+		t.Errorf("hello SEO = %q, want %q", page, want)
+	}
+	data, err := fs.ReadFile(out, "blog/hello/og.png")
+	if err != nil {
+		t.Fatalf("og.png not written: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil || img.Bounds().Dx() != 1200 || img.Bounds().Dy() != 630 {
+		t.Errorf("og.png = %v, %v; want a 1200x630 PNG", img.Bounds(), err)
+	}
+
+	page, _ = fs.ReadFile(out, "blog/custom/index.html")
+	if !strings.HasPrefix(string(page), "/static/custom.png ") {
+		t.Errorf("custom SEO = %q, want the frontmatter image", page)
+	}
+	if _, err := fs.Stat(out, "blog/custom/og.png"); err == nil {
+		t.Error("og.png rendered for a post with a frontmatter image")
+	}
+}
+
+func TestBuild_OGImagesDisabled(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md": {Data: []byte("---\ntitle: Hello\npublished: true\n---\nHi\n")},
+	}
+	if err := Build(context.Background(), ogComponents(), opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := fs.Stat(out, "blog/hello/og.png"); err == nil {
+		t.Error("og.png rendered with og_images disabled")
+	}
+}
+
+func TestNewOGImageRenderer_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		site  website.SiteConfig
+		fonts fs.FS
+	}{
+		"missing font": {
+			site:  website.SiteConfig{OGImages: website.OGImagesConfig{Enabled: true, TitleFont: "missing.ttf"}, Theme: website.DefaultTheme()},
+			fonts: fstest.MapFS{},
+		},
+		"not a font": {
+			site:  website.SiteConfig{OGImages: website.OGImagesConfig{Enabled: true, TextFont: "bad.otf"}, Theme: website.DefaultTheme()},
+			fonts: fstest.MapFS{"bad.otf": {Data: []byte("not a font")}},
+		},
+		"invalid theme color": {
+			site: website.SiteConfig{OGImages: website.OGImagesConfig{Enabled: true}, Theme: website.ThemeConfig{ColorPageBackground: "white"}},
+		},
+	}
+	for name, tt := range tests {
+		if _, err := newOGImageRenderer(tt.site, tt.fonts); err == nil {
+			t.Errorf("%s: newOGImageRenderer() error = nil, want error", name)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"maciejadamski/pkg/generator"
+)
+
+// Output is a destination for build results. Each build writes to a fresh
+// Staging that becomes visible only when committed, so a failed build never
+// replaces the previous output.
+type Output interface {
+	// Begin starts writing a new build. With incremental set, files of the
+	// previous build are visible in the staging output and kept unless
+	// overwritten or removed.
+	Begin(incremental bool) (Staging, error)
+}
+
+// OutputFS is the output of a build in progress. Names are slash-separated
+// paths relative to the output root, as in io/fs.
+type OutputFS interface {
+	fs.FS
+	// WriteFile replaces name with the bytes written by write. Parent
+	// directories are created as needed.
+	WriteFile(name string, write func(w io.Writer) error) error
+	// Remove deletes name. Removing a missing file returns an error
+	// matching fs.ErrNotExist.
+	Remove(name string) error
+}
+
+// Staging is a build in progress that is published as a whole by Commit.
+type Staging interface {
+	OutputFS
+	// Commit publishes the staged files, replacing the previous output.
+	Commit() error
+	// Discard drops the staged files. It is a no-op after Commit.
+	Discard() error
+}
+
+// DiskOutput writes builds to a directory. Builds are staged in a temporary
+// sibling directory that replaces Dir on commit.
+type DiskOutput struct {
+	Dir string
+}
+
+// NewDiskOutput returns an Output writing to dir.
+func NewDiskOutput(dir string) *DiskOutput {
+	return &DiskOutput{Dir: dir}
+}
+
+// Begin creates the staging directory, seeded with the current output
+// when incremental is set.
+func (d *DiskOutput) Begin(incremental bool) (Staging, error) {
+	staging, err := stageOutput(d.Dir, incremental)
+	if err != nil {
+		return nil, err
+	}
+	return &diskStaging{dir: staging, target: d.Dir, fsys: os.DirFS(staging)}, nil
+}
+
+// diskStaging is a staging directory that is swapped into place on commit.
+type diskStaging struct {
+	dir    string
+	target string
+	fsys   fs.FS
+}
+
+func (s *diskStaging) Open(name string) (fs.File, error) {
+	return s.fsys.Open(name)
+}
+
+func (s *diskStaging) WriteFile(name string, write func(w io.Writer) error) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	return generator.WriteFileAtomic(filepath.Join(s.dir, filepath.FromSlash(name)), 0644, write)
+}
+
+func (s *diskStaging) Remove(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.Remove(filepath.Join(s.dir, filepath.FromSlash(name)))
+}
+
+func (s *diskStaging) Commit() error {
+	return swapOutput(s.dir, s.target)
+}
+
+func (s *diskStaging) Discard() error {
+	return os.RemoveAll(s.dir)
+}
+
+// stageOutput creates an empty staging directory next to outputDir. With
+// seed set, the current output is hard-linked into it (copied where links
+// are unsupported) so unchanged files can be kept by an incremental build.
+// All writes replace files by rename, so the live output is never modified.
+func stageOutput(outputDir string, seed bool) (string, error) {
+	parent := filepath.Dir(outputDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", fmt.Errorf("creating output parent directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(outputDir)+"-build-*")
+	if err != nil {
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+	if err := os.Chmod(staging, 0755); err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+
+	if seed {
+		if err := linkTree(outputDir, staging); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			os.RemoveAll(staging)
+			return "", fmt.Errorf("seeding staging directory: %w", err)
+		}
+	}
+
+	slog.Debug("staging build", "dir", staging, "seeded", seed)
+	return staging, nil
+}
+
+// swapOutput replaces outputDir with staging. The previous output is moved
+// aside first and removed only after the new one is in place.
+func swapOutput(staging, outputDir string) error {
+	old := staging + "-old"
+	hadOutput := true
+	if err := os.Rename(outputDir, old); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("moving previous output aside: %w", err)
+		}
+		hadOutput = false
+	}
+
+	if err := os.Rename(staging, outputDir); err != nil {
+		if hadOutput {
+			if rerr := os.Rename(old, outputDir); rerr != nil {
+				slog.Error("failed to restore previous output", "path", old, "error", rerr)
+			}
+		}
+		return fmt.Errorf("replacing output directory: %w", err)
+	}
+
+	if hadOutput {
+		if err := os.RemoveAll(old); err != nil {
+			slog.Warn("failed to remove previous output", "path", old, "error", err)
+		}
+	}
+	return nil
+}
+
+// linkTree recreates the directory tree of src under dst using hard links.
+func linkTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type()&fs.ModeSymlink != 0:
+			return nil
+		}
+		if err := os.Link(path, target); err != nil {
+			return generator.CopyFile(path, target)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStageAndSwapOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	if err := os.MkdirAll(filepath.Join(outputDir, "blog"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "blog", "index.html"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	staging, err := stageOutput(outputDir, true)
+	if err != nil {
+		t.Fatalf("stageOutput() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(staging, "blog", "index.html"))
+	if err != nil || string(got) != "old" {
+		t.Fatalf("seeded file = %q, %v; want old", got, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(staging, "index.html"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "index.html")); !os.IsNotExist(err) {
+		t.Errorf("staged write visible in output before swap: %v", err)
+	}
+
+	if err := swapOutput(staging, outputDir); err != nil {
+		t.Fatalf("swapOutput() error = %v", err)
+	}
+	got, err = os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil || string(got) != "new" {
+		t.Errorf("index.html = %q, %v; want new", got, err)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("parent has %d entries, want only dist", len(entries))
+	}
+}
+
+func TestSwapOutput_NoPreviousOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+
+	staging, err := stageOutput(outputDir, true)
+	if err != nil {
+		t.Fatalf("stageOutput() error = %v", err)
+	}
+	if err := swapOutput(staging, outputDir); err != nil {
+		t.Fatalf("swapOutput() error = %v", err)
+	}
+	if info, err := os.Stat(outputDir); err != nil || !info.IsDir() {
+		t.Errorf("output directory missing after swap: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+// Plugin extends the build with lifecycle hooks. Hooks run in registration
+// order; an error from any hook fails the build. Embed NopPlugin to
+// implement only the hooks a plugin needs.
+type Plugin interface {
+	// Name identifies the plugin in logs and errors.
+	Name() string
+
+	// OnConfigLoaded runs after site config, theme, translations and authors
+	// are loaded and may modify the config.
+	OnConfigLoaded(site *website.SiteConfig) error
+
+	// OnPostsParsed receives every parsed post, drafts included, and returns
+	// the posts to build. Posts may be modified, added or removed.
+	OnPostsParsed(site website.SiteConfig, posts []markdown.Post) ([]markdown.Post, error)
+
+	// OnPageRendered receives the HTML of a rendered page and returns the
+	// bytes to write. path is relative to the output directory. Pages
+	// unchanged since the previous incremental build are not re-rendered.
+	OnPageRendered(path string, html []byte) ([]byte, error)
+
+	// OnBuildComplete runs once all outputs are written, before they replace
+	// the previous output. Files written to manifest.Output are published
+	// with the rest of the build and removed by the next build that no
+	// longer writes them. It runs after _headers is generated, so the
+	// Content-Security-Policy has no hashes for inline scripts or styles in
+	// pages a plugin writes or rewrites; list their sources in headers.csp.
+	OnBuildComplete(manifest Manifest) error
+}
+
+// Manifest describes the output of a build.
+type Manifest struct {
+	// Output holds the staged build and accepts additional files.
+	Output OutputFS
+	// Files lists every output file, slash-separated and sorted.
+	Files []string
+}
+
+// NopPlugin implements every Plugin hook as a no-op.
+type NopPlugin struct{}
+
+func (NopPlugin) OnConfigLoaded(*website.SiteConfig) error { return nil }
+
+func (NopPlugin) OnPostsParsed(_ website.SiteConfig, posts []markdown.Post) ([]markdown.Post, error) {
+	return posts, nil
+}
+
+func (NopPlugin) OnPageRendered(_ string, html []byte) ([]byte, error) { return html, nil }
+
+func (NopPlugin) OnBuildComplete(Manifest) error { return nil }
+
+// pluginList runs hooks across all registered plugins.
+type pluginList []Plugin
+
+func (pl pluginList) configLoaded(site *website.SiteConfig) error {
+	for _, p := range pl {
+		if err := p.OnConfigLoaded(site); err != nil {
+			return fmt.Errorf("plugin %s: config loaded: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (pl pluginList) postsParsed(site website.SiteConfig, posts []markdown.Post) ([]markdown.Post, error) {
+	for _, p := range pl {
+		var err error
+		if posts, err = p.OnPostsParsed(site, posts); err != nil {
+			return nil, fmt.Errorf("plugin %s: posts parsed: %w", p.Name(), err)
+		}
+	}
+	return posts, nil
+}
+
+func (pl pluginList) pageRendered(path string, html []byte) ([]byte, error) {
+	for _, p := range pl {
+		var err error
+		if html, err = p.OnPageRendered(path, html); err != nil {
+			return nil, fmt.Errorf("plugin %s: page %s: %w", p.Name(), path, err)
+		}
+	}
+	return html, nil
+}
+
+func (pl pluginList) buildComplete(out OutputFS) error {
+	if len(pl) == 0 {
+		return nil
+	}
+	manifest, err := buildManifest(out)
+	if err != nil {
+		return err
+	}
+	for _, p := range pl {
+		if err := p.OnBuildComplete(manifest); err != nil {
+			return fmt.Errorf("plugin %s: build complete: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// buildManifest lists every file in out.
+func buildManifest(out OutputFS) (Manifest, error) {
+	m := Manifest{Output: out}
+	err := fs.WalkDir(out, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		m.Files = append(m.Files, name)
+		return nil
+	})
+	if err != nil {
+		return Manifest{}, fmt.Errorf("listing build output: %w", err)
+	}
+	sort.Strings(m.Files)
+	return m, nil
+}
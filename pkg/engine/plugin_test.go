@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+// recordingPlugin exercises every hook.
+type recordingPlugin struct {
+	NopPlugin
+	manifest Manifest
+	failPage bool
+}
+
+func (p *recordingPlugin) Name() string { return "recording" }
+
+func (p *recordingPlugin) OnConfigLoaded(site *website.SiteConfig) error {
+	site.Name = "Plugged"
+	return nil
+}
+
+func (p *recordingPlugin) OnPostsParsed(_ website.SiteConfig, posts []markdown.Post) ([]markdown.Post, error) {
+	return append(posts, markdown.Post{Meta: markdown.PostMeta{Title: "Injected", Slug: "injected", Language: "en", Published: true}}), nil
+}
+
+func (p *recordingPlugin) OnPageRendered(path string, html []byte) ([]byte, error) {
+	if p.failPage {
+		return nil, errors.New("boom")
+	}
+	return bytes.ToUpper(html), nil
+}
+
+func (p *recordingPlugin) OnBuildComplete(m Manifest) error {
+	p.manifest = m
+	return m.Output.WriteFile("search.json", func(w io.Writer) error {
+		_, err := io.WriteString(w, "[]")
+		return err
+	})
+}
+
+func TestBuild_Plugins(t *testing.T) {
+	opts, out := memoryTestOptions()
+	plugin := &recordingPlugin{}
+	opts.Plugins = []Plugin{plugin}
+
+	components := ComponentRegistry{
+		Index: func(site website.SiteConfig, _ website.SEO, _ []markdown.Post) templ.Component {
+			return mockComponent{content: "home of " + site.Name}
+		},
+		BlogPost: func(_ website.SiteConfig, _ website.SEO, p markdown.Post) templ.Component {
+			return mockComponent{content: p.Meta.Title}
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	got, err := fs.ReadFile(out, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "HOME OF PLUGGED" {
+		t.Errorf("index.html = %q, want config and HTML hooks applied", got)
+	}
+
+	got, err = fs.ReadFile(out, "blog/injected/index.html")
+	if err != nil {
+		t.Fatalf("injected post not rendered: %v", err)
+	}
+	if string(got) != "INJECTED" {
+		t.Errorf("injected post = %q, want INJECTED", got)
+	}
+
+	if !slices.Contains(plugin.manifest.Files, "blog/injected/index.html") {
+		t.Errorf("manifest files = %v, want injected post", plugin.manifest.Files)
+	}
+	if _, err := fs.Stat(out, "search.json"); err != nil {
+		t.Errorf("file written in OnBuildComplete not published: %v", err)
+	}
+}
+
+func TestBuild_PluginError(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.Plugins = []Plugin{&recordingPlugin{failPage: true}}
+
+	components := ComponentRegistry{
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return mockComponent{content: "home"}
+		},
+	}
+	err := Build(context.Background(), components, opts)
+	if err == nil {
+		t.Fatal("Build() error = nil, want plugin error")
+	}
+	if _, statErr := fs.Stat(out, "index.html"); !errors.Is(statErr, fs.ErrNotExist) {
+		t.Errorf("output committed despite failed build")
+	}
+}
+
+func TestBuild_PluginFilesPruned(t *testing.T) {
+	tmpDir := t.TempDir()
+	opts := BuildOptions{
+		OutputDir: filepath.Join(tmpDir, "dist"),
+		ConfigFS:  fstest.MapFS{"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\n")}},
+		ContentFS: fstest.MapFS{},
+		StaticFS:  fstest.MapFS{},
+		CacheDir:  filepath.Join(tmpDir, "tmp", ".buildcache"),
+		Plugins:   []Plugin{&recordingPlugin{}},
+	}
+	if err := Build(context.Background(), ComponentRegistry{}, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	searchIndex := filepath.Join(opts.OutputDir, "search.json")
+	if _, err := os.Stat(searchIndex); err != nil {
+		t.Fatalf("plugin file not written: %v", err)
+	}
+
+	opts.Plugins = nil
+	if err := Build(context.Background(), ComponentRegistry{}, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := os.Stat(searchIndex); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("plugin file kept after the plugin stopped writing it: %v", err)
+	}
+}
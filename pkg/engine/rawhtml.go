@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+// rawHTMLPolicy returns the sanitizer policy for the site, or nil when raw
+// HTML is disabled.
+func rawHTMLPolicy(cfg website.RawHTMLConfig) *markdown.Policy {
+	if !cfg.Enabled {
+		return nil
+	}
+	policy := markdown.DefaultPolicy().Allow(cfg.Allow)
+	return &policy
+}
+
+// reportRawHTML logs HTML removed by the sanitizer. In strict mode any
+// removal fails the build.
+func reportRawHTML(posts []markdown.Post, strict bool) error {
+	count := 0
+	for _, post := range posts {
+		for _, v := range post.Violations {
+			slog.Warn("raw HTML removed", "post", post.Meta.Slug, "language", post.Meta.Language, "violation", v.String())
+			count++
+		}
+	}
+	if strict && count > 0 {
+		return fmt.Errorf("raw HTML sanitizer removed %d element(s) in strict mode", count)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"testing"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+func TestRawHTMLPolicy(t *testing.T) {
+	if p := rawHTMLPolicy(website.RawHTMLConfig{}); p != nil {
+		t.Errorf("rawHTMLPolicy(disabled) = %v, want nil", p)
+	}
+
+	p := rawHTMLPolicy(website.RawHTMLConfig{Enabled: true, Allow: map[string][]string{"video": {"src"}}})
+	if p == nil {
+		t.Fatal("rawHTMLPolicy(enabled) = nil")
+	}
+	if _, ok := p.Tags["video"]; !ok {
+		t.Error("policy missing allowed video tag")
+	}
+	if _, ok := p.Tags["details"]; !ok {
+		t.Error("policy missing default details tag")
+	}
+}
+
+func TestReportRawHTML(t *testing.T) {
+	posts := []markdown.Post{
+		{Meta: markdown.PostMeta{Slug: "a"}, Violations: []markdown.Violation{{Tag: "script", Reason: "tag removed with its content"}}},
+		{Meta: markdown.PostMeta{Slug: "b"}},
+	}
+
+	if err := reportRawHTML(posts, false); err != nil {
+		t.Errorf("reportRawHTML(lenient) error = %v, want nil", err)
+	}
+	if err := reportRawHTML(posts, true); err == nil {
+		t.Error("reportRawHTML(strict) error = nil, want error")
+	}
+	if err := reportRawHTML(posts[1:], true); err != nil {
+		t.Errorf("reportRawHTML(strict, clean) error = %v, want nil", err)
+	}
+}
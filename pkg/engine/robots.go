@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"maciejadamski/pkg/website"
+)
+
+// GenerateRobots writes robots.txt from the robots section of the site
+// config, followed by the site's sitemap and any extra sitemaps. Builds
+// outside production disallow every crawler unless
+// robots.index_non_production is set.
+func GenerateRobots(out OutputFS, site website.SiteConfig) error {
+	data, err := robotsTxt(site)
+	if err != nil {
+		return err
+	}
+	outPath := "robots.txt"
+	if err := out.WriteFile(outPath, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+	slog.Info("robots.txt generated", "path", outPath)
+	return nil
+}
+
+// robotsTxt renders the robots.txt content for site.
+func robotsTxt(site website.SiteConfig) ([]byte, error) {
+	cfg := site.Robots
+	if !website.IsProduction(site) && !cfg.IndexNonProduction {
+		slog.Info("disallowing crawlers outside production", "environment", site.Environment)
+		return []byte("User-agent: *\nDisallow: /\n"), nil
+	}
+
+	rules := cfg.Rules
+	if len(rules) == 0 {
+		rules = []website.RobotsRule{{UserAgents: []string{"*"}, Allow: []string{"/"}}}
+	}
+
+	var buf bytes.Buffer
+	var errs []error
+	for i, rule := range rules {
+		if err := validateRobotsRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("robots rule %d: %w", i+1, err))
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		for _, agent := range rule.UserAgents {
+			fmt.Fprintf(&buf, "User-agent: %s\n", strings.TrimSpace(agent))
+		}
+		for _, p := range rule.Allow {
+			fmt.Fprintf(&buf, "Allow: %s\n", p)
+		}
+		for _, p := range rule.Disallow {
+			fmt.Fprintf(&buf, "Disallow: %s\n", p)
+		}
+		if len(rule.Allow) == 0 && len(rule.Disallow) == 0 {
+			buf.WriteString("Allow: /\n")
+		}
+		if rule.CrawlDelay > 0 {
+			fmt.Fprintf(&buf, "Crawl-delay: %d\n", rule.CrawlDelay)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	buf.WriteString("\n")
+	fmt.Fprintf(&buf, "Sitemap: %s\n", website.AbsoluteURL(site, "/sitemap.xml"))
+	for _, sitemap := range cfg.Sitemaps {
+		fmt.Fprintf(&buf, "Sitemap: %s\n", website.AbsoluteURL(site, sitemap))
+	}
+	return buf.Bytes(), nil
+}
+
+// validateRobotsRule reports rules robots.txt parsers would misread.
+func validateRobotsRule(rule website.RobotsRule) error {
+	if len(rule.UserAgents) == 0 {
+		return errors.New("no user_agents")
+	}
+	for _, agent := range rule.UserAgents {
+		if strings.TrimSpace(agent) == "" || strings.ContainsAny(agent, "\r\n") {
+			return fmt.Errorf("invalid user agent %q", agent)
+		}
+	}
+	for _, p := range append(append([]string{}, rule.Allow...), rule.Disallow...) {
+		if p != "" && !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "*") || strings.ContainsAny(p, "\r\n") {
+			return fmt.Errorf("path %q must start with / or *", p)
+		}
+	}
+	if rule.CrawlDelay < 0 {
+		return fmt.Errorf("negative crawl_delay %d", rule.CrawlDelay)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"maciejadamski/pkg/website"
+)
+
+func TestGenerateRobots(t *testing.T) {
+	tests := []struct {
+		name string
+		site website.SiteConfig
+		want string
+	}{
+		{
+			name: "default rules",
+			site: website.SiteConfig{URL: "https://example.com"},
+			want: "User-agent: *\nAllow: /\n\nSitemap: https://example.com/sitemap.xml\n",
+		},
+		{
+			name: "per agent rules",
+			site: website.SiteConfig{
+				URL:         "https://example.com",
+				Environment: "production",
+				Robots: website.RobotsConfig{
+					Rules: []website.RobotsRule{
+						{UserAgents: []string{"GPTBot", "ClaudeBot"}, Allow: []string{"/blog/"}, Disallow: []string{"/"}},
+						{UserAgents: []string{"CCBot"}, Disallow: []string{"/"}},
+						{UserAgents: []string{"*"}, CrawlDelay: 10},
+					},
+					Sitemaps: []string{"/sitemap-extra.xml", "https://cdn.example.com/sitemap.xml"},
+				},
+			},
+			want: "User-agent: GPTBot\nUser-agent: ClaudeBot\nAllow: /blog/\nDisallow: /\n" +
+				"\nUser-agent: CCBot\nDisallow: /\n" +
+				"\nUser-agent: *\nAllow: /\nCrawl-delay: 10\n" +
+				"\nSitemap: https://example.com/sitemap.xml\n" +
+				"Sitemap: https://example.com/sitemap-extra.xml\n" +
+				"Sitemap: https://cdn.example.com/sitemap.xml\n",
+		},
+		{
+			name: "non-production",
+			site: website.SiteConfig{
+				URL:         "https://example.com",
+				Environment: "development",
+				Robots:      website.RobotsConfig{Rules: []website.RobotsRule{{UserAgents: []string{"*"}}}},
+			},
+			want: "User-agent: *\nDisallow: /\n",
+		},
+		{
+			name: "non-production indexed",
+			site: website.SiteConfig{
+				URL:         "https://example.com",
+				Environment: "staging",
+				Robots:      website.RobotsConfig{IndexNonProduction: true},
+			},
+			want: "User-agent: *\nAllow: /\n\nSitemap: https://example.com/sitemap.xml\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := NewMemoryOutput().Begin(false)
+			if err := GenerateRobots(out, tt.site); err != nil {
+				t.Fatalf("GenerateRobots() error = %v", err)
+			}
+			content, err := fs.ReadFile(out, "robots.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("robots.txt = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRobots_InvalidRules(t *testing.T) {
+	site := website.SiteConfig{
+		URL: "https://example.com",
+		Robots: website.RobotsConfig{Rules: []website.RobotsRule{
+			{Disallow: []string{"/"}},
+			{UserAgents: []string{"GPTBot"}, Disallow: []string{"private"}},
+			{UserAgents: []string{"*"}, CrawlDelay: -1},
+		}},
+	}
+
+	out, _ := NewMemoryOutput().Begin(false)
+	err := GenerateRobots(out, site)
+	if err == nil {
+		t.Fatal("GenerateRobots() expected error, got nil")
+	}
+	for _, want := range []string{"rule 1", "rule 2", "rule 3"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %s", err, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"golang.org/x/net/html"
+)
+
+// maxSitemapURLs is the per-file URL limit of the sitemap protocol. Larger
+// sitemaps are split into numbered files listed by a sitemap index.
+const maxSitemapURLs = 50000
+
+// SitemapPage is a page listed in the sitemap.
+type SitemapPage struct {
+	// Path is the URL path of the page ("/blog/my-post/").
+	Path     string
+	LastMod  time.Time
+	Priority string
+	// NoIndex pages are left out of the sitemap.
+	NoIndex bool
+	// Images are absolute URLs or site paths of images shown on the page.
+	Images     []string
+	Alternates []website.Alternate
+}
+
+// sitemapURL is a single <url> entry with absolute URLs.
+type sitemapURL struct {
+	Loc        string
+	LastMod    string
+	Priority   string
+	Images     []string
+	Alternates []sitemapAlternate
+}
+
+// sitemapAlternate is an <xhtml:link> alternate for a translated page.
+type sitemapAlternate struct {
+	Language string
+	Href     string
+}
+
+// GenerateSitemap writes sitemap.xml listing every indexable page with its
+// lastmod, images and hreflang alternates. Beyond 50,000 URLs the entries
+// are split into sitemap-1.xml, sitemap-2.xml, ... and sitemap.xml becomes a
+// sitemap index.
+func GenerateSitemap(out OutputFS, site website.SiteConfig, pages []SitemapPage) error {
+	return writeSitemaps(out, site, sitemapURLs(site, pages), maxSitemapURLs)
+}
+
+// writeSitemaps writes urls as one sitemap, or as numbered sitemaps and an
+// index when there are more than limit.
+func writeSitemaps(out OutputFS, site website.SiteConfig, urls []sitemapURL, limit int) error {
+	if len(urls) <= limit {
+		if err := writeXML(out, "sitemap.xml", newURLSet(urls)); err != nil {
+			return err
+		}
+		slog.Info("sitemap generated", "path", "sitemap.xml", "urls", len(urls))
+		return nil
+	}
+
+	index := xmlSitemapIndex{Xmlns: sitemapNS}
+	for i, chunk := 0, urls; len(chunk) > 0; i++ {
+		n := min(limit, len(chunk))
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		if err := writeXML(out, name, newURLSet(chunk[:n])); err != nil {
+			return err
+		}
+		index.Sitemaps = append(index.Sitemaps, xmlSitemap{
+			Loc:     website.AbsoluteURL(site, "/"+name),
+			LastMod: latestLastMod(chunk[:n]),
+		})
+		chunk = chunk[n:]
+	}
+	if err := writeXML(out, "sitemap.xml", index); err != nil {
+		return err
+	}
+
+	slog.Info("sitemap index generated", "path", "sitemap.xml", "urls", len(urls), "sitemaps", len(index.Sitemaps))
+	return nil
+}
+
+// sitemapURLs converts pages to absolute sitemap entries, dropping noindex pages.
+func sitemapURLs(site website.SiteConfig, pages []SitemapPage) []sitemapURL {
+	var urls []sitemapURL
+	for _, p := range pages {
+		if p.NoIndex {
+			continue
+		}
+		u := sitemapURL{
+			Loc:        website.AbsoluteURL(site, p.Path),
+			Priority:   p.Priority,
+			Alternates: sitemapAlternates(site, p.Alternates),
+		}
+		if !p.LastMod.IsZero() {
+			u.LastMod = p.LastMod.Format(time.RFC3339)
+		}
+		for _, img := range p.Images {
+			u.Images = append(u.Images, website.AbsoluteURL(site, img))
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// sitemapAlternates converts page alternates to absolute sitemap links.
+func sitemapAlternates(site website.SiteConfig, alternates []website.Alternate) []sitemapAlternate {
+	var links []sitemapAlternate
+	for _, a := range alternates {
+		links = append(links, sitemapAlternate{Language: a.Language, Href: website.AbsoluteURL(site, a.Path)})
+	}
+	return links
+}
+
+// sitemapPages describes the pages of a build for the sitemap. Pages
+// without dated posts use buildTime as their lastmod.
+func sitemapPages(site website.SiteConfig, pages []renderedPage, buildTime time.Time) []SitemapPage {
+	var out []SitemapPage
+	for _, p := range pages {
+		seo := p.Inputs.SEO
+		sp := SitemapPage{
+			Path:       p.Path,
+			Priority:   sitemapPriority(seo),
+			NoIndex:    seo.NoIndex,
+			Alternates: seo.Alternates,
+		}
+		for _, post := range p.Inputs.Posts {
+			if t := postLastMod(site, post); t.After(sp.LastMod) {
+				sp.LastMod = t
+			}
+		}
+		if sp.LastMod.IsZero() {
+			sp.LastMod = buildTime
+		}
+		if seo.OGImage != "" {
+			sp.Images = append(sp.Images, seo.OGImage)
+		}
+		if seo.IsArticle {
+			for _, post := range p.Inputs.Posts {
+				for _, src := range contentImages(post.Content) {
+					if !slices.Contains(sp.Images, src) {
+						sp.Images = append(sp.Images, src)
+					}
+				}
+			}
+		}
+		out = append(out, sp)
+	}
+	return out
+}
+
+// sitemapPriority ranks pages by type.
+func sitemapPriority(seo website.SEO) string {
+	switch {
+	case seo.IsHomePage:
+		return "1.0"
+	case seo.IsBlogIndex:
+		return "0.8"
+	case seo.IsArticle:
+		return "0.6"
+	default:
+		return "0.5"
+	}
+}
+
+// postLastMod returns when a post last changed: its "updated" frontmatter
+// date if valid, otherwise its publication date.
+func postLastMod(site website.SiteConfig, post markdown.Post) time.Time {
+	if v, ok := post.Meta.Extra["updated"].(string); ok {
+		if t, err := markdown.ParseDate(v, website.GetLocation(site)); err == nil && !t.IsZero() {
+			return t
+		}
+	}
+	return post.Meta.Time
+}
+
+// contentImages returns the src of every <img> in rendered HTML, skipping
+// inline data URIs.
+func contentImages(content string) []string {
+	var srcs []string
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return srcs
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if tok.Data != "img" {
+			continue
+		}
+		for _, a := range tok.Attr {
+			if a.Key == "src" && a.Val != "" && !strings.HasPrefix(a.Val, "data:") {
+				srcs = append(srcs, a.Val)
+			}
+		}
+	}
+}
+
+// latestLastMod returns the newest lastmod of urls (RFC3339 sorts lexically
+// only within one zone, so values are compared as times).
+func latestLastMod(urls []sitemapURL) string {
+	var latest time.Time
+	var value string
+	for _, u := range urls {
+		if t, err := time.Parse(time.RFC3339, u.LastMod); err == nil && t.After(latest) {
+			latest, value = t, u.LastMod
+		}
+	}
+	return value
+}
+
+const (
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	xhtmlNS   = "http://www.w3.org/1999/xhtml"
+	imageNS   = "http://www.google.com/schemas/sitemap-image/1.1"
+)
+
+type xmlURLSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Xhtml   string   `xml:"xmlns:xhtml,attr"`
+	Image   string   `xml:"xmlns:image,attr"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type xmlURL struct {
+	Loc        string         `xml:"loc"`
+	LastMod    string         `xml:"lastmod,omitempty"`
+	Priority   string         `xml:"priority,omitempty"`
+	Alternates []xmlAlternate `xml:"xhtml:link"`
+	Images     []xmlImage     `xml:"image:image"`
+}
+
+type xmlAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type xmlImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+type xmlSitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []xmlSitemap `xml:"sitemap"`
+}
+
+type xmlSitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func newURLSet(urls []sitemapURL) xmlURLSet {
+	set := xmlURLSet{Xmlns: sitemapNS, Xhtml: xhtmlNS, Image: imageNS}
+	for _, u := range urls {
+		x := xmlURL{Loc: u.Loc, LastMod: u.LastMod, Priority: u.Priority}
+		for _, a := range u.Alternates {
+			x.Alternates = append(x.Alternates, xmlAlternate{Rel: "alternate", Hreflang: a.Language, Href: a.Href})
+		}
+		for _, img := range u.Images {
+			x.Images = append(x.Images, xmlImage{Loc: img})
+		}
+		set.URLs = append(set.URLs, x)
+	}
+	return set
+}
+
+// writeXML writes v as an indented XML document to name.
+func writeXML(out OutputFS, name string, v any) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding %s: %w", name, err)
+	}
+	buf.WriteByte('\n')
+	return out.WriteFile(name, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+func TestGenerateSitemap(t *testing.T) {
+	site := website.SiteConfig{URL: "https://example.com"}
+	published := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	pages := []SitemapPage{
+		{Path: "/", LastMod: published, Priority: "1.0"},
+		{
+			Path:       "/blog/post-1/",
+			LastMod:    published,
+			Priority:   "0.6",
+			Images:     []string{"/static/cover.png"},
+			Alternates: []website.Alternate{{Language: "pl", Path: "/pl/blog/post-1/"}},
+		},
+		{Path: "/blog/hidden/", LastMod: published, NoIndex: true},
+	}
+
+	out, _ := NewMemoryOutput().Begin(false)
+	if err := GenerateSitemap(out, site, pages); err != nil {
+		t.Fatalf("GenerateSitemap() error = %v", err)
+	}
+
+	content, err := fs.ReadFile(out, "sitemap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var set struct {
+		URLs []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+			Images  []struct {
+				Loc string `xml:"loc"`
+			} `xml:"image"`
+			Links []struct {
+				Hreflang string `xml:"hreflang,attr"`
+				Href     string `xml:"href,attr"`
+			} `xml:"link"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(content, &set); err != nil {
+		t.Fatalf("sitemap is not valid XML: %v\n%s", err, content)
+	}
+
+	if len(set.URLs) != 2 {
+		t.Fatalf("sitemap has %d URLs, want 2 (noindex excluded):\n%s", len(set.URLs), content)
+	}
+	post := set.URLs[1]
+	if post.Loc != "https://example.com/blog/post-1/" {
+		t.Errorf("loc = %q", post.Loc)
+	}
+	if post.LastMod != "2026-01-15T12:00:00Z" {
+		t.Errorf("lastmod = %q, want 2026-01-15T12:00:00Z", post.LastMod)
+	}
+	if len(post.Images) != 1 || post.Images[0].Loc != "https://example.com/static/cover.png" {
+		t.Errorf("images = %+v, want absolute cover URL", post.Images)
+	}
+	if len(post.Links) != 1 || post.Links[0].Href != "https://example.com/pl/blog/post-1/" {
+		t.Errorf("alternates = %+v", post.Links)
+	}
+}
+
+func TestWriteSitemaps_Index(t *testing.T) {
+	site := website.SiteConfig{URL: "https://example.com"}
+	var urls []sitemapURL
+	for _, p := range []string{"a", "b", "c", "d", "e"} {
+		urls = append(urls, sitemapURL{Loc: "https://example.com/" + p + "/", LastMod: "2026-01-15T00:00:00Z"})
+	}
+
+	out, _ := NewMemoryOutput().Begin(false)
+	if err := writeSitemaps(out, site, urls, 2); err != nil {
+		t.Fatalf("writeSitemaps() error = %v", err)
+	}
+
+	index, err := fs.ReadFile(out, "sitemap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "<sitemapindex") {
+		t.Fatalf("sitemap.xml is not an index:\n%s", index)
+	}
+	for _, name := range []string{"sitemap-1.xml", "sitemap-2.xml", "sitemap-3.xml"} {
+		if !strings.Contains(string(index), "https://example.com/"+name) {
+			t.Errorf("index missing %s", name)
+		}
+		if _, err := fs.Stat(out, name); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+	last, _ := fs.ReadFile(out, "sitemap-3.xml")
+	if strings.Count(string(last), "<url>") != 1 {
+		t.Errorf("sitemap-3.xml has %d URLs, want 1", strings.Count(string(last), "<url>"))
+	}
+}
+
+func TestSitemapPages(t *testing.T) {
+	site := website.SiteConfig{URL: "https://example.com"}
+	built := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	older := markdown.Post{
+		Meta:    markdown.PostMeta{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Content: `<p><img src="/static/a.png" alt="a"><img src="data:image/png;base64,xx"></p>`,
+	}
+	updated := markdown.Post{
+		Meta: markdown.PostMeta{
+			Time:  time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			Extra: map[string]any{"updated": "2026-02-01"},
+		},
+	}
+
+	pages := sitemapPages(site, []renderedPage{
+		{Path: "/", Inputs: pageInputs{SEO: website.SEO{IsHomePage: true}}},
+		{Path: "/blog/", Inputs: pageInputs{SEO: website.SEO{IsBlogIndex: true}, Posts: []markdown.Post{older, updated}}},
+		{Path: "/blog/a/", Inputs: pageInputs{SEO: website.SEO{IsArticle: true, OGImage: "/static/cover.png"}, Posts: []markdown.Post{older}}},
+	}, built)
+
+	if !pages[0].LastMod.Equal(built) || pages[0].Priority != "1.0" {
+		t.Errorf("home = %+v, want build time and priority 1.0", pages[0])
+	}
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC); !pages[1].LastMod.Equal(want) {
+		t.Errorf("blog index lastmod = %v, want %v from updated field", pages[1].LastMod, want)
+	}
+	if got := pages[2].Images; len(got) != 2 || got[0] != "/static/cover.png" || got[1] != "/static/a.png" {
+		t.Errorf("post images = %v, want cover and content image", got)
+	}
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+)
+
+// TemplateData is the data context of *.tmpl files in the static and public
+// inputs.
+type TemplateData struct {
+	Site website.SiteConfig
+	// Posts are the published posts of every language, newest first.
+	Posts []markdown.Post
+	// Pages are the rendered pages, as listed in the sitemap.
+	Pages []SitemapPage
+	Build BuildInfo
+}
+
+// BuildInfo describes the running build.
+type BuildInfo struct {
+	Time        time.Time
+	Environment string
+	Production  bool
+}
+
+// templateFuncs are available in *.tmpl files besides the text/template builtins.
+func templateFuncs(site website.SiteConfig) template.FuncMap {
+	return template.FuncMap{
+		"absURL": func(p string) string { return website.AbsoluteURL(site, p) },
+		"asset":  func(p string) string { return website.Asset(site, p) },
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
+
+// renderTemplates renders every *.tmpl file in src with text/template and
+// writes it into dir in out without the suffix ("site.webmanifest.tmpl" ->
+// "site.webmanifest"). Dotfiles are skipped as when copying.
+func renderTemplates(ctx context.Context, out OutputFS, cache *buildCache, src fs.FS, dir string, data TemplateData) error {
+	if src == nil {
+		return nil
+	}
+	if _, err := fs.Stat(src, "."); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	var errs []error
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := checkCanceled(ctx, "rendering templates"); err != nil {
+			return err
+		}
+		if hidden(name) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || path.Ext(name) != ".tmpl" {
+			return nil
+		}
+
+		if err := renderTemplate(out, cache, src, name, path.Join(dir, strings.TrimSuffix(name, ".tmpl")), data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// hasTemplate reports whether src holds a template for the output file name.
+func hasTemplate(src fs.FS, name string) bool {
+	if src == nil {
+		return false
+	}
+	_, err := fs.Stat(src, name+".tmpl")
+	return err == nil
+}
+
+// renderTemplate renders the template name from src to outName in out.
+// HTML and CSS output is minified like the rendered pages.
+func renderTemplate(out OutputFS, cache *buildCache, src fs.FS, name, outName string, data TemplateData) error {
+	text, err := fs.ReadFile(src, name)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(path.Base(name)).Funcs(templateFuncs(data.Site)).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	output := buf.Bytes()
+	if cache.minify != nil {
+		switch path.Ext(outName) {
+		case ".html":
+			output = cache.minify.HTML(output)
+		case ".css":
+			output = cache.minify.CSS(output)
+		}
+	}
+	if err := cache.writeFile(out, outName, output); err != nil {
+		return err
+	}
+	slog.Debug("rendered template", "source", name, "path", outName)
+	return nil
+}
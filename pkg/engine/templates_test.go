@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuild_Templates(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\nheaders:\n  enabled: true\n")},
+	}
+	opts.ContentFS = fstest.MapFS{
+		"blog/hello.md": {Data: []byte("---\ntitle: Hello\npublished: true\n---\nHi\n")},
+	}
+	opts.StaticFS = fstest.MapFS{
+		"css/vars.css.tmpl": {Data: []byte(`:root{--name:{{ .Site.Name | printf "%q" }}}`)},
+	}
+	opts.PublicFS = fstest.MapFS{
+		"site.webmanifest.tmpl": {Data: []byte(`{"name":{{ json .Site.Name }},"start_url":{{ absURL "/" | json }}}`)},
+		"_headers.tmpl":         {Data: []byte("/*\n  X-Env: {{ .Build.Environment }}{{ if .Build.Production }}production{{ end }}\n")},
+		".well-known/security.txt.tmpl": {Data: []byte("Canonical: {{ absURL \"/.well-known/security.txt\" }}\n" +
+			"{{ range .Posts }}# {{ .Meta.Title }}\n{{ end }}")},
+	}
+	if err := Build(context.Background(), ComponentRegistry{}, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"static/css/vars.css":      `:root{--name:"Test"}`,
+		"site.webmanifest":         `{"name":"Test","start_url":"https://example.com/"}`,
+		"_headers":                 "/*\n  X-Env: production\n",
+		".well-known/security.txt": "Canonical: https://example.com/.well-known/security.txt\n# Hello\n",
+	} {
+		got, err := fs.ReadFile(out, name)
+		if err != nil {
+			t.Errorf("%s not rendered: %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBuild_TemplateError(t *testing.T) {
+	opts, _ := memoryTestOptions()
+	opts.PublicFS = fstest.MapFS{
+		"broken.txt.tmpl": {Data: []byte("{{ .Missing }}")},
+	}
+
+	err := Build(context.Background(), ComponentRegistry{}, opts)
+	if err == nil || !strings.Contains(err.Error(), "broken.txt.tmpl") {
+		t.Errorf("Build() error = %v, want error naming broken.txt.tmpl", err)
+	}
+}
+
+func TestBuild_RemovedTemplateKeepsGeneratedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	opts := BuildOptions{
+		OutputDir:  filepath.Join(tmpDir, "dist"),
+		ConfigDir:  filepath.Join(tmpDir, "config"),
+		ContentDir: filepath.Join(tmpDir, "content"),
+		StaticDir:  filepath.Join(tmpDir, "static"),
+		PublicDir:  filepath.Join(tmpDir, "public"),
+		CacheDir:   filepath.Join(tmpDir, "tmp", ".buildcache"),
+	}
+	for _, dir := range []string{opts.ConfigDir, opts.PublicDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(opts.ConfigDir, "site.yaml"), []byte("name: Test\nurl: https://example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	override := filepath.Join(opts.PublicDir, "robots.txt.tmpl")
+	if err := os.WriteFile(override, []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	robots := func() string {
+		t.Helper()
+		if err := Build(context.Background(), ComponentRegistry{}, opts); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(opts.OutputDir, "robots.txt"))
+		if err != nil {
+			t.Fatalf("robots.txt missing: %v", err)
+		}
+		return string(data)
+	}
+
+	if got := robots(); got != "custom" {
+		t.Errorf("robots.txt = %q, want the template output", got)
+	}
+	if err := os.Remove(override); err != nil {
+		t.Fatal(err)
+	}
+	if got := robots(); !strings.HasPrefix(got, "User-agent: *") {
+		t.Errorf("robots.txt = %q, want the generated file", got)
+	}
+}
+
+func TestBuild_HTMLTemplateMinifiedAndHashed(t *testing.T) {
+	opts, out := memoryTestOptions()
+	opts.ConfigFS = fstest.MapFS{
+		"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\nheaders:\n  enabled: true\nminify:\n  enabled: true\n")},
+	}
+	opts.PublicFS = fstest.MapFS{
+		"landing.html.tmpl": {Data: []byte("<!DOCTYPE html>\n<html>\n  <body>\n    <p>  {{ .Site.Name }}  </p>\n    <script>console.log(1)</script>\n  </body>\n</html>\n")},
+	}
+	if err := Build(context.Background(), ComponentRegistry{}, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	page, err := fs.ReadFile(out, "landing.html")
+	if err != nil {
+		t.Fatalf("landing.html not rendered: %v", err)
+	}
+	if strings.Contains(string(page), "\n    ") {
+		t.Errorf("landing.html = %q, want minified HTML", page)
+	}
+	headers, _ := fs.ReadFile(out, "_headers")
+	if want := cspHash("console.log(1)"); !strings.Contains(string(headers), want) {
+		t.Errorf("_headers missing the template's script hash %s:\n%s", want, headers)
+	}
+}
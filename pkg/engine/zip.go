@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"sort"
+
+	"maciejadamski/pkg/generator"
+)
+
+// ZipOutput writes each build as a zip archive at Path. The archive is
+// written atomically on commit; builds are always full.
+type ZipOutput struct {
+	Path string
+}
+
+// NewZipOutput returns an Output writing a zip archive to path.
+func NewZipOutput(path string) *ZipOutput {
+	return &ZipOutput{Path: path}
+}
+
+// Begin starts collecting a build in memory.
+func (z *ZipOutput) Begin(bool) (Staging, error) {
+	return &memoryStaging{files: memFS{}, commit: z.write}, nil
+}
+
+// write stores files as a zip archive at z.Path, sorted by name.
+func (z *ZipOutput) write(files memFS) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	err := generator.WriteFileAtomic(z.Path, 0644, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		for _, name := range names {
+			f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(files[name]); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("writing zip output: %w", err)
+	}
+	return nil
+}
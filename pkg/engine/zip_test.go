@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+func TestBuild_ZipOutput(t *testing.T) {
+	opts, _ := memoryTestOptions()
+	archive := filepath.Join(t.TempDir(), "site.zip")
+	opts.Output = NewZipOutput(archive)
+
+	components := ComponentRegistry{
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return mockComponent{content: "home"}
+		},
+	}
+	if err := Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer r.Close()
+
+	f, err := r.Open("index.html")
+	if err != nil {
+		t.Fatalf("archive missing index.html: %v", err)
+	}
+	defer f.Close()
+	got, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "home" {
+		t.Errorf("index.html = %q, want home", got)
+	}
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"sort"
+	"strings"
+)
+
+// fingerprintLen is the number of hex digits of the content hash in
+// fingerprinted names.
+const fingerprintLen = 8
+
+// FingerprintName inserts a short hash of data before the extension of name
+// ("css/prose.css" -> "css/prose.3f9a1c2b.css").
+func FingerprintName(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:fingerprintLen] + ext
+}
+
+// AssetRewriter replaces references to original asset paths with their
+// fingerprinted paths. Only quoted references ("/static/a.css",
+// '/static/a.css') and CSS url(/static/a.css) are rewritten, so a path
+// never matches as a prefix of a longer one.
+type AssetRewriter struct {
+	r *strings.Replacer
+}
+
+// NewAssetRewriter returns a rewriter for assets, which maps original paths
+// to fingerprinted paths.
+func NewAssetRewriter(assets map[string]string) *AssetRewriter {
+	originals := make([]string, 0, len(assets))
+	for orig := range assets {
+		originals = append(originals, orig)
+	}
+	sort.Strings(originals)
+
+	var pairs []string
+	for _, orig := range originals {
+		fp := assets[orig]
+		pairs = append(pairs,
+			`"`+orig+`"`, `"`+fp+`"`,
+			`'`+orig+`'`, `'`+fp+`'`,
+			`(`+orig+`)`, `(`+fp+`)`,
+		)
+	}
+	return &AssetRewriter{r: strings.NewReplacer(pairs...)}
+}
+
+// Rewrite returns src with every asset reference replaced.
+func (a *AssetRewriter) Rewrite(src []byte) []byte {
+	return []byte(a.r.Replace(string(src)))
+}
@@ -0,0 +1,44 @@
+package generator
+
+import "testing"
+
+func TestFingerprintName(t *testing.T) {
+	a := FingerprintName("static/css/prose.css", []byte("body{}"))
+	b := FingerprintName("static/css/prose.css", []byte("body{margin:0}"))
+
+	if a == b {
+		t.Errorf("different content got the same name %q", a)
+	}
+	if a != FingerprintName("static/css/prose.css", []byte("body{}")) {
+		t.Error("same content got different names")
+	}
+	if len(a) != len("static/css/prose.12345678.css") || a[:len("static/css/prose.")] != "static/css/prose." || a[len(a)-4:] != ".css" {
+		t.Errorf("FingerprintName() = %q, want static/css/prose.<hash>.css", a)
+	}
+}
+
+func TestAssetRewriter(t *testing.T) {
+	r := NewAssetRewriter(map[string]string{
+		"/static/a.css": "/static/a.1234abcd.css",
+		"/static/a.js":  "/static/a.5678abcd.js",
+	})
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"double quoted", `<link href="/static/a.css">`, `<link href="/static/a.1234abcd.css">`},
+		{"single quoted", `<script src='/static/a.js'></script>`, `<script src='/static/a.5678abcd.js'></script>`},
+		{"css url", `<div style="background:url(/static/a.css)">`, `<div style="background:url(/static/a.1234abcd.css)">`},
+		{"longer path untouched", `<link href="/static/a.css.map">`, `<link href="/static/a.css.map">`},
+		{"text untouched", `<p>see /static/a.css</p>`, `<p>see /static/a.css</p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.Rewrite([]byte(tt.src))); got != tt.want {
+				t.Errorf("Rewrite() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
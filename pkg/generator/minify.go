@@ -0,0 +1,265 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// Minifier minifies build output and keeps count of the bytes it saved.
+// It is safe for concurrent use.
+type Minifier struct {
+	mu     sync.Mutex
+	files  int
+	before int64
+	after  int64
+}
+
+// NewMinifier returns a Minifier with zeroed statistics.
+func NewMinifier() *Minifier {
+	return &Minifier{}
+}
+
+// HTML minifies an HTML document and records the bytes saved.
+func (m *Minifier) HTML(src []byte) []byte {
+	return m.record(src, MinifyHTML(src))
+}
+
+// CSS minifies a stylesheet and records the bytes saved.
+func (m *Minifier) CSS(src []byte) []byte {
+	return m.record(src, MinifyCSS(src))
+}
+
+func (m *Minifier) record(src, out []byte) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files++
+	m.before += int64(len(src))
+	m.after += int64(len(out))
+	return out
+}
+
+// Stats returns the number of minified files and their total size before
+// and after minification.
+func (m *Minifier) Stats() (files int, before, after int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files, m.before, m.after
+}
+
+// blockTags are elements around which whitespace does not render, so it can
+// be dropped instead of collapsed.
+var blockTags = map[string]bool{
+	"html": true, "head": true, "body": true, "title": true, "meta": true, "link": true,
+	"script": true, "style": true, "noscript": true, "template": true, "base": true,
+	"div": true, "p": true, "ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true,
+	"nav": true, "header": true, "footer": true, "main": true, "section": true, "article": true, "aside": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "br": true,
+	"table": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "td": true, "th": true,
+	"caption": true, "colgroup": true, "col": true,
+	"form": true, "fieldset": true, "legend": true, "select": true, "option": true, "optgroup": true,
+	"figure": true, "figcaption": true, "blockquote": true, "details": true, "summary": true,
+	"dialog": true, "pre": true, "textarea": true,
+}
+
+// preservedTags keep their content byte for byte.
+var preservedTags = map[string]bool{"pre": true, "textarea": true}
+
+// MinifyHTML removes comments and insignificant whitespace from an HTML
+// document. Whitespace runs collapse to one space, and disappear next to
+// block-level tags. Inline <style> blocks are minified as CSS and JSON-LD
+// scripts are compacted; other scripts are only trimmed. The content of
+// <pre> and <textarea> is preserved.
+func MinifyHTML(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+
+	z := html.NewTokenizer(bytes.NewReader(src))
+	var (
+		preserved int    // depth of open <pre> and <textarea> elements
+		rawTag    string // script or style element whose content is next
+		rawType   string // type attribute of rawTag
+		pending   []byte // text waiting for the next tag to decide its edges
+		prevBlock = true // whether the previous tag was block-level
+	)
+
+	flush := func(nextBlock bool) {
+		if pending == nil {
+			return
+		}
+		text := collapseSpace(pending)
+		if prevBlock {
+			text = bytes.TrimLeft(text, " ")
+		}
+		if nextBlock {
+			text = bytes.TrimRight(text, " ")
+		}
+		out.Write(text)
+		pending = nil
+	}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			flush(true)
+			return out.Bytes()
+
+		case html.TextToken:
+			raw := z.Raw()
+			switch {
+			case rawTag == "style":
+				out.Write(MinifyCSS(raw))
+			case rawTag == "script":
+				out.Write(minifyScript(raw, rawType))
+			case preserved > 0:
+				out.Write(raw)
+			default:
+				pending = append(pending, raw...)
+			}
+
+		case html.CommentToken:
+			if preserved > 0 {
+				out.Write(z.Raw())
+			}
+
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			raw := append([]byte(nil), z.Raw()...)
+			name, hasAttr := z.TagName()
+			tag := string(name)
+			block := blockTags[tag]
+			if preserved == 0 {
+				flush(block)
+			}
+			out.Write(raw)
+			prevBlock = block
+
+			rawTag = ""
+			switch {
+			case tt == html.StartTagToken && (tag == "script" || tag == "style"):
+				rawTag, rawType = tag, attrValue(z, hasAttr, "type")
+			case tt == html.StartTagToken && preservedTags[tag]:
+				preserved++
+			case tt == html.EndTagToken && preservedTags[tag] && preserved > 0:
+				preserved--
+			}
+
+		default:
+			flush(true)
+			out.Write(z.Raw())
+			prevBlock = true
+		}
+	}
+}
+
+// attrValue returns the value of the named attribute of the current tag.
+func attrValue(z *html.Tokenizer, hasAttr bool, name string) string {
+	for hasAttr {
+		var key, val []byte
+		key, val, hasAttr = z.TagAttr()
+		if string(key) == name {
+			return strings.ToLower(string(val))
+		}
+	}
+	return ""
+}
+
+// collapseSpace replaces every run of HTML whitespace with a single space.
+func collapseSpace(text []byte) []byte {
+	out := make([]byte, 0, len(text))
+	space := false
+	for _, c := range text {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			out = append(out, ' ')
+			space = false
+		}
+		out = append(out, c)
+	}
+	if space {
+		out = append(out, ' ')
+	}
+	return out
+}
+
+// minifyScript compacts JSON-LD and trims other scripts, which are not
+// rewritten.
+func minifyScript(src []byte, typ string) []byte {
+	if typ == "application/ld+json" || typ == "application/json" {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, src); err == nil {
+			return buf.Bytes()
+		}
+	}
+	return bytes.TrimSpace(src)
+}
+
+// MinifyCSS removes comments and insignificant whitespace from a stylesheet.
+// Strings are left untouched. Spaces before ":" are kept, since they are
+// significant in selectors ("a :hover").
+func MinifyCSS(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	space := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				end = len(src) - 1
+			}
+			if space {
+				out = appendCSSSpace(out, c)
+				space = false
+			}
+			out = append(out, src[i:end+1]...)
+			i = end
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+			space = true
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+
+		default:
+			if c == '}' && len(out) > 0 && out[len(out)-1] == ';' {
+				out = out[:len(out)-1]
+			}
+			if space {
+				out = appendCSSSpace(out, c)
+				space = false
+			}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// appendCSSSpace appends the space pending before next unless the
+// characters on either side make it insignificant.
+func appendCSSSpace(out []byte, next byte) []byte {
+	if len(out) == 0 || strings.IndexByte("{};,>~", next) >= 0 {
+		return out
+	}
+	if strings.IndexByte("{};,>~:", out[len(out)-1]) >= 0 {
+		return out
+	}
+	return append(out, ' ')
+}
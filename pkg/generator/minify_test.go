@@ -0,0 +1,111 @@
+package generator
+
+import "testing"
+
+func TestMinifyHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "whitespace around block tags",
+			src:  "<!doctype html>\n<html>\n  <head>\n    <title> Hello </title>\n  </head>\n  <body>\n    <p>\n      Hi\n    </p>\n  </body>\n</html>\n",
+			want: "<!doctype html><html><head><title>Hello</title></head><body><p>Hi</p></body></html>",
+		},
+		{
+			name: "inline whitespace collapses to one space",
+			src:  "<p>Read   <a href=\"/\">this</a>\n  <em>now</em> .</p>",
+			want: "<p>Read <a href=\"/\">this</a> <em>now</em> .</p>",
+		},
+		{
+			name: "comments removed",
+			src:  "<div><!-- note --><span>x</span></div>",
+			want: "<div><span>x</span></div>",
+		},
+		{
+			name: "pre and textarea preserved",
+			src:  "<div>\n<pre><code>a  :=  1\n\n  b</code>  </pre>\n<textarea>  keep\n  me </textarea></div>",
+			want: "<div><pre><code>a  :=  1\n\n  b</code>  </pre><textarea>  keep\n  me </textarea></div>",
+		},
+		{
+			name: "inline style minified",
+			src:  "<style>\n  :root {\n    --a: 1px;\n  }\n</style>",
+			want: "<style>:root{--a:1px}</style>",
+		},
+		{
+			name: "json-ld compacted",
+			src:  "<script type=\"application/ld+json\">\n{\n  \"@type\": \"Person\",\n  \"name\": \"A  B\"\n}\n</script>",
+			want: "<script type=\"application/ld+json\">{\"@type\":\"Person\",\"name\":\"A  B\"}</script>",
+		},
+		{
+			name: "other scripts only trimmed",
+			src:  "<script>\n  var a = 1;\n  var b = 2;\n</script>",
+			want: "<script>var a = 1;\n  var b = 2;</script>",
+		},
+		{
+			name: "entities kept",
+			src:  "<p>a &amp;  b</p>",
+			want: "<p>a &amp; b</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifyHTML([]byte(tt.src))); got != tt.want {
+				t.Errorf("MinifyHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifyCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "rules and declarations",
+			src:  "/* theme */\n.a ,\n.b > p {\n  color: red;\n  margin: 0 auto;\n}\n",
+			want: ".a,.b>p{color:red;margin:0 auto}",
+		},
+		{
+			name: "descendant pseudo-class keeps space",
+			src:  ".prose :where(a) { color: blue }",
+			want: ".prose :where(a){color:blue}",
+		},
+		{
+			name: "strings untouched",
+			src:  "a::before { content: \"  /* x */  \"; font-family: 'A  B', serif; }",
+			want: "a::before{content:\"  /* x */  \";font-family:'A  B',serif}",
+		},
+		{
+			name: "calc and media queries",
+			src:  "@media (min-width: 768px) and (max-width: 1024px) {\n  .a { width: calc(100% - 2rem); }\n}",
+			want: "@media (min-width:768px) and (max-width:1024px){.a{width:calc(100% - 2rem)}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifyCSS([]byte(tt.src))); got != tt.want {
+				t.Errorf("MinifyCSS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifier_Stats(t *testing.T) {
+	m := NewMinifier()
+	m.HTML([]byte("<p>  a  </p>"))
+	m.CSS([]byte("a { color: red; }"))
+
+	files, before, after := m.Stats()
+	if files != 2 {
+		t.Errorf("files = %d, want 2", files)
+	}
+	if before != 29 || after != 20 {
+		t.Errorf("before, after = %d, %d, want 29, 20", before, after)
+	}
+}
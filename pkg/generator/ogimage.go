@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Open Graph image size recommended by Facebook, LinkedIn and X.
+const (
+	OGImageWidth  = 1200
+	OGImageHeight = 630
+)
+
+// ogPadding is the margin around the card content, in pixels.
+const ogPadding = 80
+
+// ogTitleSizes are the title font sizes tried in order until the title fits
+// in ogTitleLines lines. The smallest size truncates the title.
+var ogTitleSizes = []float64{72, 64, 56, 48}
+
+const ogTitleLines = 3
+
+// OGCard is the text drawn on an Open Graph image.
+type OGCard struct {
+	Title  string
+	Date   string
+	Author string
+	// Site is the branding shown above the title, e.g. the site name.
+	Site string
+	// Host is shown in the bottom right corner, e.g. "example.com".
+	Host string
+}
+
+// OGStyle holds the colors and fonts of an Open Graph image.
+type OGStyle struct {
+	Background color.Color
+	Accent     color.Color
+	Title      color.Color
+	Text       color.Color
+	Muted      color.Color
+	Border     color.Color
+	TitleFont  *opentype.Font
+	TextFont   *opentype.Font
+}
+
+// ParseFont parses a TrueType (.ttf) or OpenType (.otf) font.
+func ParseFont(data []byte) (*opentype.Font, error) {
+	return opentype.Parse(data)
+}
+
+// ParseHexColor parses a "#rgb" or "#rrggbb" color.
+func ParseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// RenderOGImage draws card as an OGImageWidth x OGImageHeight PNG: the site
+// branding at the top, the title wrapped below it and the author, date and
+// host in the footer.
+func RenderOGImage(card OGCard, style OGStyle) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, OGImageWidth, OGImageHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(style.Background), image.Point{}, draw.Src)
+	// Accent bar along the left edge.
+	draw.Draw(img, image.Rect(0, 0, 16, OGImageHeight), image.NewUniform(style.Accent), image.Point{}, draw.Src)
+
+	width := OGImageWidth - 2*ogPadding
+
+	brand, err := newFace(style.TitleFont, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer brand.Close()
+	drawText(img, brand, style.Accent, ogPadding, ogPadding+32, truncate(brand, card.Site, width))
+
+	title, lines, err := fitTitle(style.TitleFont, card.Title, width)
+	if err != nil {
+		return nil, err
+	}
+	defer title.Close()
+	lineHeight := title.Metrics().Height.Ceil() * 6 / 5
+	y := ogPadding + 32 + 60 + title.Metrics().Ascent.Ceil()
+	for _, line := range lines {
+		drawText(img, title, style.Title, ogPadding, y, line)
+		y += lineHeight
+	}
+
+	footerY := OGImageHeight - ogPadding
+	draw.Draw(img, image.Rect(ogPadding, footerY-64, OGImageWidth-ogPadding, footerY-62), image.NewUniform(style.Border), image.Point{}, draw.Src)
+
+	text, err := newFace(style.TextFont, 28)
+	if err != nil {
+		return nil, err
+	}
+	defer text.Close()
+	host := card.Host
+	hostWidth := font.MeasureString(text, host).Ceil()
+	drawText(img, text, style.Muted, OGImageWidth-ogPadding-hostWidth, footerY, host)
+
+	var meta []string
+	for _, s := range []string{card.Author, card.Date} {
+		if s != "" {
+			meta = append(meta, s)
+		}
+	}
+	drawText(img, text, style.Text, ogPadding, footerY, truncate(text, strings.Join(meta, " · "), width-hostWidth-40))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("creating font face: %w", err)
+	}
+	return face, nil
+}
+
+// fitTitle returns the largest title face at which the title wraps into at
+// most ogTitleLines lines, truncating it at the smallest size.
+func fitTitle(f *opentype.Font, title string, width int) (font.Face, []string, error) {
+	last := len(ogTitleSizes) - 1
+	for _, size := range ogTitleSizes[:last] {
+		face, err := newFace(f, size)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lines := wrap(face, title, width); len(lines) <= ogTitleLines {
+			return face, lines, nil
+		}
+		face.Close()
+	}
+
+	face, err := newFace(f, ogTitleSizes[last])
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := wrap(face, title, width)
+	if len(lines) > ogTitleLines {
+		lines = lines[:ogTitleLines]
+		lines[ogTitleLines-1] = truncate(face, lines[ogTitleLines-1]+"…", width)
+	}
+	return face, lines, nil
+}
+
+// wrap breaks text into lines no wider than width, splitting words that
+// don't fit on a line of their own.
+func wrap(face font.Face, text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+		for font.MeasureString(face, line).Ceil() > width {
+			head := fitRunes(face, line, width)
+			lines = append(lines, head)
+			line = line[len(head):]
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncate shortens text with an ellipsis until it fits in width.
+func truncate(face font.Face, text string, width int) string {
+	if font.MeasureString(face, text).Ceil() <= width {
+		return text
+	}
+	return fitRunes(face, text, width-font.MeasureString(face, "…").Ceil()) + "…"
+}
+
+// fitRunes returns the longest prefix of text, of at least one rune, that
+// fits in width.
+func fitRunes(face font.Face, text string, width int) string {
+	end := 0
+	for i, r := range text {
+		next := i + utf8.RuneLen(r)
+		if end > 0 && font.MeasureString(face, text[:next]).Ceil() > width {
+			break
+		}
+		end = next
+	}
+	return text[:end]
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, text string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
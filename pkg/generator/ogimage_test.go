@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func testOGStyle(t *testing.T) OGStyle {
+	t.Helper()
+	bold, err := ParseFont(gobold.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regular, err := ParseFont(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return OGStyle{
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Accent:     color.RGBA{0x52, 0x00, 0x8d, 0xff},
+		Title:      color.RGBA{0x12, 0x13, 0x17, 0xff},
+		Text:       color.RGBA{0x5f, 0x63, 0x68, 0xff},
+		Muted:      color.RGBA{0x70, 0x75, 0x7a, 0xff},
+		Border:     color.RGBA{0xda, 0xdc, 0xe0, 0xff},
+		TitleFont:  bold,
+		TextFont:   regular,
+	}
+}
+
+func TestRenderOGImage(t *testing.T) {
+	style := testOGStyle(t)
+	for _, title := range []string{
+		"Short",
+		strings.Repeat("A very long title that keeps going ", 10),
+		strings.Repeat("Unbreakable", 20),
+	} {
+		data, err := RenderOGImage(OGCard{Title: title, Date: "January 2, 2026", Author: "Jane", Site: "Site", Host: "example.com"}, style)
+		if err != nil {
+			t.Fatalf("RenderOGImage(%q) error = %v", title, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("decoding PNG: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != OGImageWidth || b.Dy() != OGImageHeight {
+			t.Errorf("size = %v, want %dx%d", b.Size(), OGImageWidth, OGImageHeight)
+		}
+		for _, p := range []struct {
+			x, y int
+			want color.Color
+		}{
+			{OGImageWidth - 1, 0, style.Background},
+			{0, OGImageHeight / 2, style.Accent},
+		} {
+			if got := color.RGBAModel.Convert(img.At(p.x, p.y)); got != p.want {
+				t.Errorf("pixel (%d, %d) = %v, want %v", p.x, p.y, got, p.want)
+			}
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	style := testOGStyle(t)
+	face, err := newFace(style.TitleFont, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer face.Close()
+
+	lines := wrap(face, "one two three four five six seven eight nine ten "+strings.Repeat("z", 80), 400)
+	if len(lines) < 4 {
+		t.Fatalf("wrap() = %q, want several lines", lines)
+	}
+	var joined []string
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > 400 {
+			t.Errorf("line %q is %dpx wide, want at most 400", line, w)
+		}
+		joined = append(joined, line)
+	}
+	if got := strings.Join(joined, ""); strings.Count(got, "z") != 80 {
+		t.Errorf("wrap() lost text: %q", lines)
+	}
+
+	if got := truncate(face, strings.Repeat("word ", 40), 300); !strings.HasSuffix(got, "…") || font.MeasureString(face, got).Ceil() > 300 {
+		t.Errorf("truncate() = %q, want an ellipsis within 300px", got)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"#52008d", color.RGBA{0x52, 0x00, 0x8d, 0xff}, false},
+		{"#fff", color.RGBA{0xff, 0xff, 0xff, 0xff}, false},
+		{" #000000 ", color.RGBA{0, 0, 0, 0xff}, false},
+		{"rgb(0,0,0)", color.RGBA{}, true},
+		{"#12345", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHexColor(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHexColor(%q) = %v, %v; want %v, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -3,34 +3,29 @@ package generator
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
-	"path/filepath"
 
 	"github.com/a-h/templ"
 )
 
 // RenderTemplComponent renders a templ component to an HTML file.
-// Creates parent directories if they don't exist.
-func RenderTemplComponent(path string, component templ.Component) error {
+// Creates parent directories if they don't exist. The file is written to a
+// temporary name and renamed into place, so a failed or canceled render
+// never leaves truncated HTML behind. ctx is passed to the component.
+func RenderTemplComponent(ctx context.Context, path string, component templ.Component) error {
 	slog.Debug("rendering component", "path", path)
 
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", dir, err)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-
-	f, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("creating file %s: %w", path, err)
-	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			slog.Error("failed to close file", "path", path, "error", err)
+	err := WriteFileAtomic(path, 0644, func(w io.Writer) error {
+		if err := component.Render(ctx, w); err != nil {
+			return err
 		}
-	}()
-
-	if err := component.Render(context.Background(), f); err != nil {
+		return ctx.Err()
+	})
+	if err != nil {
 		return fmt.Errorf("rendering component to %s: %w", path, err)
 	}
 
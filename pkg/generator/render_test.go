@@ -2,6 +2,7 @@ package generator
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -23,7 +24,7 @@ func TestRenderTemplComponent(t *testing.T) {
 
 	comp := mockComponent{content: "<html><body>Hello</body></html>"}
 
-	if err := RenderTemplComponent(outFile, comp); err != nil {
+	if err := RenderTemplComponent(context.Background(), outFile, comp); err != nil {
 		t.Fatalf("RenderTemplComponent() error = %v", err)
 	}
 
@@ -44,7 +45,7 @@ func TestRenderTemplComponent_CreateDir(t *testing.T) {
 
 	comp := mockComponent{content: "nested"}
 
-	if err := RenderTemplComponent(outFile, comp); err != nil {
+	if err := RenderTemplComponent(context.Background(), outFile, comp); err != nil {
 		t.Fatalf("RenderTemplComponent() error = %v", err)
 	}
 
@@ -57,3 +58,41 @@ func TestRenderTemplComponent_CreateDir(t *testing.T) {
 		t.Errorf("content = %q, want nested", string(got))
 	}
 }
+
+// failingComponent writes partial output and then fails.
+type failingComponent struct{}
+
+func (failingComponent) Render(ctx context.Context, w io.Writer) error {
+	if _, err := w.Write([]byte("<html><bo")); err != nil {
+		return err
+	}
+	return errors.New("render failed")
+}
+
+func TestRenderTemplComponent_FailureKeepsExisting(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "output.html")
+	if err := os.WriteFile(outFile, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenderTemplComponent(context.Background(), outFile, failingComponent{}); err == nil {
+		t.Fatal("RenderTemplComponent() error = nil, want error")
+	}
+
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "previous" {
+		t.Errorf("content = %q, want previous content untouched", got)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want temp file removed", len(entries))
+	}
+}
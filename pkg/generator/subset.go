@@ -0,0 +1,369 @@
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// ErrSubsetUnsupported is returned by SubsetFont for fonts it cannot subset:
+// WOFF, WOFF2, collections and fonts with CFF outlines.
+var ErrSubsetUnsupported = errors.New("only TrueType fonts with glyf outlines can be subset")
+
+// Composite glyph flags (OpenType glyf table).
+const (
+	argsAreWords   = 0x0001
+	haveScale      = 0x0008
+	moreComponents = 0x0020
+	haveXYScale    = 0x0040
+	haveTwoByTwo   = 0x0080
+)
+
+// tableRecordSize is the size of a table directory entry.
+const tableRecordSize = 16
+
+// droppedTables are removed from subset fonts: substitutions can map kept
+// characters to removed glyphs, and a digital signature no longer matches.
+var droppedTables = map[string]bool{"GSUB": true, "morx": true, "mort": true, "DSIG": true}
+
+type fontTable struct {
+	tag  string
+	data []byte
+}
+
+// SubsetFont returns a copy of a TrueType font in which only the glyphs of
+// runes, the .notdef glyph and the components of those glyphs keep their
+// outlines. The character map only lists runes, so browsers render other
+// characters with the next font in the stack. Glyph IDs are unchanged, so
+// metrics and kerning still apply; the other glyphs are left empty.
+func SubsetFont(data []byte, runes []rune) ([]byte, error) {
+	if len(data) < 12 || binary.BigEndian.Uint32(data) != 0x00010000 && string(data[:4]) != "true" {
+		return nil, ErrSubsetUnsupported
+	}
+	tables, err := readTables(data)
+	if err != nil {
+		return nil, err
+	}
+	head, loca, glyf, maxp := tableIndex(tables, "head"), tableIndex(tables, "loca"), tableIndex(tables, "glyf"), tableIndex(tables, "maxp")
+	if glyf < 0 || loca < 0 {
+		return nil, ErrSubsetUnsupported
+	}
+	if head < 0 || maxp < 0 || len(tables[head].data) < 54 || len(tables[maxp].data) < 6 {
+		return nil, errors.New("font has no valid head or maxp table")
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(tables[maxp].data[4:]))
+	offsets, err := glyphOffsets(tables[loca].data, numGlyphs, binary.BigEndian.Uint16(tables[head].data[50:]) == 1)
+	if err != nil {
+		return nil, err
+	}
+	glyphs := tables[glyf].data
+	glyph := func(id int) []byte {
+		start, end := offsets[id], offsets[id+1]
+		if start >= end || end > uint32(len(glyphs)) {
+			return nil
+		}
+		return glyphs[start:end]
+	}
+
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing font: %w", err)
+	}
+	keep := make([]bool, numGlyphs)
+	queue := []int{0}
+	var mapped []cmapEntry
+	var buf sfnt.Buffer
+	for _, r := range runes {
+		if id, err := f.GlyphIndex(&buf, r); err == nil && id != 0 && int(id) < numGlyphs {
+			queue = append(queue, int(id))
+			mapped = append(mapped, cmapEntry{r: r, glyph: uint16(id)})
+		}
+	}
+	cmap, err := buildCmap(mapped)
+	if err != nil {
+		return nil, err
+	}
+	for len(queue) > 0 {
+		id := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if id >= numGlyphs || keep[id] {
+			continue
+		}
+		keep[id] = true
+		queue = append(queue, components(glyph(id))...)
+	}
+
+	// Rebuild glyf with long loca offsets, keeping glyphs 4-byte aligned.
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for id := range numGlyphs {
+		binary.BigEndian.PutUint32(newLoca[4*id:], uint32(len(newGlyf)))
+		if keep[id] {
+			newGlyf = append(newGlyf, glyph(id)...)
+			newGlyf = append(newGlyf, make([]byte, pad4(len(newGlyf)))...)
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(len(newGlyf)))
+
+	newHead := slices.Clone(tables[head].data)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+	tables[head].data = newHead
+	tables[loca].data = newLoca
+	tables[glyf].data = newGlyf
+	if i := tableIndex(tables, "cmap"); i >= 0 {
+		tables[i].data = cmap
+	} else {
+		tables = append(tables, fontTable{tag: "cmap", data: cmap})
+	}
+	tables = slices.DeleteFunc(tables, func(t fontTable) bool { return droppedTables[t.tag] })
+	return writeFont(binary.BigEndian.Uint32(data), tables), nil
+}
+
+func readTables(data []byte) ([]fontTable, error) {
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+n*tableRecordSize {
+		return nil, errors.New("font table directory is truncated")
+	}
+	tables := make([]fontTable, 0, n)
+	for i := range n {
+		record := data[12+i*tableRecordSize:]
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("font table %q is truncated", record[:4])
+		}
+		tables = append(tables, fontTable{tag: string(record[:4]), data: data[offset : offset+length]})
+	}
+	return tables, nil
+}
+
+func tableIndex(tables []fontTable, tag string) int {
+	return slices.IndexFunc(tables, func(t fontTable) bool { return t.tag == tag })
+}
+
+// glyphOffsets decodes the loca table into numGlyphs+1 offsets into glyf.
+func glyphOffsets(loca []byte, numGlyphs int, long bool) ([]uint32, error) {
+	size := 2
+	if long {
+		size = 4
+	}
+	if len(loca) < size*(numGlyphs+1) {
+		return nil, errors.New("font loca table is truncated")
+	}
+	offsets := make([]uint32, numGlyphs+1)
+	for i := range offsets {
+		if long {
+			offsets[i] = binary.BigEndian.Uint32(loca[4*i:])
+		} else {
+			offsets[i] = 2 * uint32(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+	}
+	return offsets, nil
+}
+
+// components returns the glyph IDs a composite glyph is built from.
+func components(glyph []byte) []int {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+	var ids []int
+	for p := 10; p+4 <= len(glyph); {
+		flags := binary.BigEndian.Uint16(glyph[p:])
+		ids = append(ids, int(binary.BigEndian.Uint16(glyph[p+2:])))
+		p += 4
+		if flags&argsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			p += 2
+		case flags&haveXYScale != 0:
+			p += 4
+		case flags&haveTwoByTwo != 0:
+			p += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return ids
+}
+
+type cmapEntry struct {
+	r     rune
+	glyph uint16
+}
+
+// buildCmap returns a cmap table mapping entries, sorted by rune: a Windows
+// Unicode BMP subtable (format 4) and, for runes beyond the BMP, a Windows
+// Unicode full repertoire subtable (format 12).
+func buildCmap(entries []cmapEntry) ([]byte, error) {
+	slices.SortFunc(entries, func(a, b cmapEntry) int { return int(a.r - b.r) })
+	entries = slices.CompactFunc(entries, func(a, b cmapEntry) bool { return a.r == b.r })
+
+	var bmp []cmapEntry
+	for _, e := range entries {
+		if e.r < 0xFFFF {
+			bmp = append(bmp, e)
+		}
+	}
+	format4, err := cmapFormat4(bmp)
+	if err != nil {
+		return nil, err
+	}
+	subtables := [][]byte{format4}
+	if len(bmp) < len(entries) {
+		subtables = append(subtables, cmapFormat12(entries))
+	}
+
+	// Encoding records: platform 3 (Windows), encoding 1 (BMP) and 10 (full).
+	encodings := []uint16{1, 10}
+	out := make([]byte, 4+8*len(subtables))
+	binary.BigEndian.PutUint16(out[2:], uint16(len(subtables)))
+	for i, sub := range subtables {
+		record := out[4+8*i:]
+		binary.BigEndian.PutUint16(record, 3)
+		binary.BigEndian.PutUint16(record[2:], encodings[i])
+		binary.BigEndian.PutUint32(record[4:], uint32(len(out)))
+		out = append(out, sub...)
+	}
+	return out, nil
+}
+
+// cmapFormat4 encodes BMP entries as one segment per run of consecutive
+// code points, with glyph IDs looked up through glyphIdArray.
+func cmapFormat4(entries []cmapEntry) ([]byte, error) {
+	type segment struct{ start, end int }
+	var segments []segment
+	for i, e := range entries {
+		if i > 0 && e.r == entries[i-1].r+1 {
+			segments[len(segments)-1].end = i
+			continue
+		}
+		segments = append(segments, segment{i, i})
+	}
+	n := len(segments) + 1 // plus the required final 0xFFFF segment
+	size := 16 + 8*n + 2*len(entries)
+	if size > 0xFFFF {
+		return nil, errors.New("too many characters for a format 4 cmap")
+	}
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := 2 << entrySelector
+
+	out := make([]byte, size)
+	binary.BigEndian.PutUint16(out, 4)
+	binary.BigEndian.PutUint16(out[2:], uint16(size))
+	binary.BigEndian.PutUint16(out[6:], uint16(2*n))
+	binary.BigEndian.PutUint16(out[8:], uint16(searchRange))
+	binary.BigEndian.PutUint16(out[10:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[12:], uint16(2*n-searchRange))
+	endCodes := out[14:]
+	startCodes := out[16+2*n:]
+	idDeltas := out[16+4*n:]
+	idRangeOffsets := out[16+6*n:]
+	glyphIDs := out[16+8*n:]
+	for i, seg := range segments {
+		binary.BigEndian.PutUint16(endCodes[2*i:], uint16(entries[seg.end].r))
+		binary.BigEndian.PutUint16(startCodes[2*i:], uint16(entries[seg.start].r))
+		// The offset is relative to this segment's idRangeOffset entry.
+		binary.BigEndian.PutUint16(idRangeOffsets[2*i:], uint16(2*(n-i)+2*seg.start))
+	}
+	last := len(segments)
+	binary.BigEndian.PutUint16(endCodes[2*last:], 0xFFFF)
+	binary.BigEndian.PutUint16(startCodes[2*last:], 0xFFFF)
+	binary.BigEndian.PutUint16(idDeltas[2*last:], 1)
+	for i, e := range entries {
+		binary.BigEndian.PutUint16(glyphIDs[2*i:], e.glyph)
+	}
+	return out, nil
+}
+
+// cmapFormat12 encodes entries as groups of consecutive code points that map
+// to consecutive glyph IDs.
+func cmapFormat12(entries []cmapEntry) []byte {
+	var groups [][3]uint32 // start code, end code, start glyph
+	for i, e := range entries {
+		if i > 0 {
+			g := &groups[len(groups)-1]
+			if uint32(e.r) == g[1]+1 && uint32(e.glyph) == g[2]+uint32(e.r)-g[0] {
+				g[1] = uint32(e.r)
+				continue
+			}
+		}
+		groups = append(groups, [3]uint32{uint32(e.r), uint32(e.r), uint32(e.glyph)})
+	}
+	out := make([]byte, 16+12*len(groups))
+	binary.BigEndian.PutUint16(out, 12)
+	binary.BigEndian.PutUint32(out[4:], uint32(len(out)))
+	binary.BigEndian.PutUint32(out[12:], uint32(len(groups)))
+	for i, g := range groups {
+		for j, v := range g {
+			binary.BigEndian.PutUint32(out[16+12*i+4*j:], v)
+		}
+	}
+	return out
+}
+
+// writeFont serializes tables sorted by tag, with fresh checksums.
+func writeFont(version uint32, tables []fontTable) []byte {
+	slices.SortFunc(tables, func(a, b fontTable) int { return strings.Compare(a.tag, b.tag) })
+	n := len(tables)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	out := make([]byte, 12+n*tableRecordSize)
+	binary.BigEndian.PutUint32(out, version)
+	binary.BigEndian.PutUint16(out[4:], uint16(n))
+	binary.BigEndian.PutUint16(out[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(n*16-searchRange))
+
+	headOffset := -1
+	for i, t := range tables {
+		data := t.data
+		if t.tag == "head" {
+			data = slices.Clone(data)
+			binary.BigEndian.PutUint32(data[8:], 0)
+			headOffset = len(out)
+		}
+		record := out[12+i*tableRecordSize:]
+		copy(record, t.tag)
+		binary.BigEndian.PutUint32(record[4:], checksum(data))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(data)))
+		out = append(out, data...)
+		out = append(out, make([]byte, pad4(len(out)))...)
+	}
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-checksum(out))
+	}
+	return out
+}
+
+// checksum sums data as big-endian uint32 values, zero-padded.
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+func pad4(n int) int {
+	return (4 - n%4) % 4
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestSubsetFont(t *testing.T) {
+	data, err := SubsetFont(goregular.TTF, []rune("Hé"))
+	if err != nil {
+		t.Fatalf("SubsetFont() error = %v", err)
+	}
+	if len(data) >= len(goregular.TTF)/2 {
+		t.Errorf("subset is %d bytes, want well below %d", len(data), len(goregular.TTF))
+	}
+	if got := checksum(data); got != 0xB1B0AFBA {
+		t.Errorf("font checksum = %#x, want 0xb1b0afba", got)
+	}
+
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		t.Fatalf("parsing subset: %v", err)
+	}
+	orig, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf sfnt.Buffer
+	for _, r := range "Hé" {
+		id, err := f.GlyphIndex(&buf, r)
+		if err != nil || id == 0 {
+			t.Fatalf("GlyphIndex(%q) = %d, %v; want the original mapping", r, id, err)
+		}
+		if want, _ := orig.GlyphIndex(&buf, r); id != want {
+			t.Errorf("GlyphIndex(%q) = %d, want the original ID %d", r, id, want)
+		}
+		segments, err := f.LoadGlyph(&buf, id, fixed.I(12), nil)
+		if err != nil || len(segments) == 0 {
+			t.Errorf("LoadGlyph(%q) = %d segments, %v; want the outline", r, len(segments), err)
+		}
+		if adv, err := f.GlyphAdvance(&buf, id, fixed.I(12), font.HintingNone); err != nil || adv == 0 {
+			t.Errorf("GlyphAdvance(%q) = %v, %v; want the original advance", r, adv, err)
+		}
+	}
+	for _, r := range "eZ" {
+		if id, err := f.GlyphIndex(&buf, r); err != nil || id != 0 {
+			t.Errorf("GlyphIndex(%q) = %d, %v; want no cmap entry", r, id, err)
+		}
+		id, _ := orig.GlyphIndex(&buf, r)
+		if segments, _ := f.LoadGlyph(&buf, id, fixed.I(12), nil); len(segments) > 0 {
+			t.Errorf("dropped glyph %q still has an outline", r)
+		}
+	}
+}
+
+func TestBuildCmap(t *testing.T) {
+	entries := []cmapEntry{{'b', 5}, {'a', 4}, {'c', 9}, {'x', 2}, {0x1F600, 7}, {0x1F601, 8}, {'a', 4}}
+	cmap, err := buildCmap(entries)
+	if err != nil {
+		t.Fatalf("buildCmap() error = %v", err)
+	}
+	tables, err := readTables(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables[tableIndex(tables, "cmap")].data = cmap
+	f, err := sfnt.Parse(writeFont(0x00010000, tables))
+	if err != nil {
+		t.Fatalf("parsing font with the built cmap: %v", err)
+	}
+	var buf sfnt.Buffer
+	for r, want := range map[rune]sfnt.GlyphIndex{'a': 4, 'b': 5, 'c': 9, 'd': 0, 'x': 2, 0x1F600: 7, 0x1F601: 8, 0x1F602: 0} {
+		if got, err := f.GlyphIndex(&buf, r); err != nil || got != want {
+			t.Errorf("GlyphIndex(%U) = %d, %v; want %d", r, got, err, want)
+		}
+	}
+
+	bmpOnly, _ := buildCmap([]cmapEntry{{'a', 4}})
+	if n := binary.BigEndian.Uint16(bmpOnly[2:]); n != 1 {
+		t.Errorf("BMP-only cmap has %d subtables, want 1", n)
+	}
+}
+
+func TestSubsetFont_Unsupported(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"woff2": []byte("wOF2\x00\x01\x00\x00\x00\x00\x00\x00"),
+		"cff":   []byte("OTTO\x00\x01\x00\x00\x00\x00\x00\x00"),
+		"short": []byte("ttf"),
+	} {
+		if _, err := SubsetFont(data, []rune("a")); !errors.Is(err, ErrSubsetUnsupported) {
+			t.Errorf("%s: SubsetFont() error = %v, want ErrSubsetUnsupported", name, err)
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	glyph := []byte{
+		0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, // numberOfContours -1, bounding box
+		0x00, 0x21, 0x00, 0x05, 1, 2, 3, 4, // more components, word args
+		0x00, 0x08, 0x00, 0x07, 1, 2, 0, 1, // byte args and a scale
+	}
+	if got := components(glyph); len(got) != 2 || got[0] != 5 || got[1] != 7 {
+		t.Errorf("components() = %v, want [5 7]", got)
+	}
+	if got := components([]byte{0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}); got != nil {
+		t.Errorf("components(simple glyph) = %v, want none", got)
+	}
+}
@@ -50,7 +50,7 @@ func CopyDir(src, dst string) error {
 }
 
 // CopyFile copies a single file from src to dst.
-// File permissions are preserved.
+// File permissions are preserved. dst is replaced atomically.
 func CopyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -58,23 +58,51 @@ func CopyFile(src, dst string) error {
 	}
 	defer in.Close()
 
-	out, err := os.Create(dst)
+	info, err := in.Stat()
 	if err != nil {
-		return fmt.Errorf("create destination %s: %w", dst, err)
+		return fmt.Errorf("stat source %s: %w", src, err)
 	}
-	defer out.Close()
 
-	if _, err := io.Copy(out, in); err != nil {
+	err = WriteFileAtomic(dst, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
 	}
 
-	info, err := os.Stat(src)
+	return nil
+}
+
+// WriteFileAtomic writes a file by calling write on a temporary file in the
+// same directory and renaming it to path on success. Parent directories are
+// created as needed. On failure the temporary file is removed and any
+// existing file at path is left untouched.
+func WriteFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
 	if err != nil {
-		return fmt.Errorf("stat source %s: %w", src, err)
+		return fmt.Errorf("creating temp file for %s: %w", path, err)
 	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op after a successful rename
 
-	if err := os.Chmod(dst, info.Mode()); err != nil {
-		return fmt.Errorf("chmod destination %s: %w", dst, err)
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", tmp, err)
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return fmt.Errorf("chmod %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("renaming %s to %s: %w", tmp, path, err)
 	}
 
 	return nil
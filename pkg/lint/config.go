@@ -0,0 +1,89 @@
+package lint
+
+import (
+	"fmt"
+	"maciejadamski/pkg/audit"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule names, as used in output and in the config file.
+const (
+	RuleFrontmatter        = "frontmatter"
+	RuleTitleMissing       = "title-missing"
+	RuleTitleLength        = "title-length"
+	RuleDescriptionMissing = "description-missing"
+	RuleDescriptionLength  = "description-length"
+	RuleInvalidDate        = "invalid-date"
+	RuleEmptyPost          = "empty-post"
+	RuleImageAlt           = "image-alt"
+	RuleHeadingH1          = "heading-h1"
+	RuleHeadingSkip        = "heading-skip"
+)
+
+// RuleConfig configures a single rule. Min and Max only apply to length rules.
+type RuleConfig struct {
+	Enabled  bool           `yaml:"enabled"`
+	Severity audit.Severity `yaml:"severity"`
+	Min      int            `yaml:"min"`
+	Max      int            `yaml:"max"`
+}
+
+// Config holds the configuration of every rule, keyed by rule name.
+type Config struct {
+	Rules map[string]RuleConfig `yaml:"rules"`
+}
+
+// DefaultConfig returns the built-in rule configuration.
+func DefaultConfig() Config {
+	return Config{Rules: map[string]RuleConfig{
+		RuleFrontmatter:        {Enabled: true, Severity: audit.SeverityError},
+		RuleTitleMissing:       {Enabled: true, Severity: audit.SeverityError},
+		RuleTitleLength:        {Enabled: true, Severity: audit.SeverityWarning, Max: 60},
+		RuleDescriptionMissing: {Enabled: true, Severity: audit.SeverityError},
+		RuleDescriptionLength:  {Enabled: true, Severity: audit.SeverityWarning, Min: 50, Max: 160},
+		RuleInvalidDate:        {Enabled: true, Severity: audit.SeverityError},
+		RuleEmptyPost:          {Enabled: true, Severity: audit.SeverityError},
+		RuleImageAlt:           {Enabled: true, Severity: audit.SeverityError},
+		RuleHeadingH1:          {Enabled: true, Severity: audit.SeverityError},
+		RuleHeadingSkip:        {Enabled: true, Severity: audit.SeverityWarning},
+	}}
+}
+
+// LoadConfig reads rule overrides from a YAML file on top of DefaultConfig.
+// Fields omitted for a rule keep their defaults. Returns defaults if the file doesn't exist.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("reading lint config: %w", err)
+	}
+
+	var raw struct {
+		Rules map[string]yaml.Node `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return cfg, fmt.Errorf("parsing lint config: %w", err)
+	}
+
+	for name, node := range raw.Rules {
+		rule, ok := cfg.Rules[name]
+		if !ok {
+			return cfg, fmt.Errorf("lint config: unknown rule %q", name)
+		}
+		if err := node.Decode(&rule); err != nil {
+			return cfg, fmt.Errorf("lint config: rule %q: %w", name, err)
+		}
+		if rule.Severity != audit.SeverityError && rule.Severity != audit.SeverityWarning {
+			return cfg, fmt.Errorf("lint config: rule %q: invalid severity %q", name, rule.Severity)
+		}
+		cfg.Rules[name] = rule
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/markdown"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Issue is a single problem found in a content file.
+type Issue struct {
+	File     string         `json:"file"`
+	Line     int            `json:"line"`
+	Rule     string         `json:"rule"`
+	Severity audit.Severity `json:"severity"`
+	Message  string         `json:"message"`
+}
+
+// String formats the issue as "file:line: rule: message".
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Rule, i.Message)
+}
+
+// rawImgTag matches raw HTML <img> tags.
+var rawImgTag = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+
+// altAttr matches an alt attribute inside a tag.
+var altAttr = regexp.MustCompile(`(?i)\salt\s*=`)
+
+// Dir lints every markdown file under dir, recursively.
+// Issues are sorted by file and line.
+func Dir(dir string, cfg Config, opts markdown.Options) ([]Issue, error) {
+	var issues []Issue
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		fileIssues, err := File(path, cfg, opts)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, fileIssues...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("linting %s: %w", dir, err)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// File lints a single markdown file.
+// Frontmatter that cannot be decoded is reported as an issue, not an error.
+func File(path string, cfg Config, opts markdown.Options) ([]Issue, error) {
+	l := linter{path: path, cfg: cfg}
+
+	src, err := markdown.ReadSource(path)
+	if err != nil {
+		var fmErr *markdown.FrontmatterError
+		if errors.As(err, &fmErr) {
+			l.report(RuleFrontmatter, fmErr.Line, "%v", fmErr.Err)
+			return l.issues, nil
+		}
+		return nil, err
+	}
+
+	meta, err := src.Meta(opts)
+	if err != nil {
+		l.report(RuleInvalidDate, src.FieldLine("date"), "%v", err)
+	}
+
+	l.checkMeta(src, meta)
+	l.checkBody(src)
+
+	slog.Debug("linted file", "path", path, "issues", len(l.issues))
+	return l.issues, nil
+}
+
+// linter collects issues for one file.
+type linter struct {
+	path   string
+	cfg    Config
+	issues []Issue
+}
+
+// report records an issue if the rule is enabled.
+func (l *linter) report(rule string, line int, format string, args ...any) {
+	rc, ok := l.cfg.Rules[rule]
+	if !ok || !rc.Enabled {
+		return
+	}
+	l.issues = append(l.issues, Issue{
+		File:     l.path,
+		Line:     line,
+		Rule:     rule,
+		Severity: rc.Severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkMeta checks title and description presence and length.
+func (l *linter) checkMeta(src *markdown.Source, meta markdown.PostMeta) {
+	if strings.TrimSpace(meta.Title) == "" {
+		l.report(RuleTitleMissing, src.FieldLine("title"), "title is missing")
+	} else if limit := l.cfg.Rules[RuleTitleLength].Max; limit > 0 {
+		if n := utf8.RuneCountInString(meta.Title); n > limit {
+			l.report(RuleTitleLength, src.FieldLine("title"), "title is %d characters, max %d", n, limit)
+		}
+	}
+
+	if strings.TrimSpace(meta.Description) == "" {
+		l.report(RuleDescriptionMissing, src.FieldLine("description"), "description is missing")
+		return
+	}
+	rc := l.cfg.Rules[RuleDescriptionLength]
+	n := utf8.RuneCountInString(meta.Description)
+	switch {
+	case rc.Min > 0 && n < rc.Min:
+		l.report(RuleDescriptionLength, src.FieldLine("description"), "description is %d characters, min %d", n, rc.Min)
+	case rc.Max > 0 && n > rc.Max:
+		l.report(RuleDescriptionLength, src.FieldLine("description"), "description is %d characters, max %d", n, rc.Max)
+	}
+}
+
+// checkBody walks the markdown body for empty content, image alt text and heading structure.
+func (l *linter) checkBody(src *markdown.Source) {
+	if len(bytes.TrimSpace(src.Body)) == 0 {
+		l.report(RuleEmptyPost, src.BodyLine, "post has no content")
+		return
+	}
+
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src.Body))
+	lineOf := func(offset int) int {
+		return src.BodyLine + bytes.Count(src.Body[:offset], []byte("\n"))
+	}
+
+	// The page template renders the title as the only H1.
+	prevLevel := 1
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Heading:
+			line := lineOf(nodeOffset(node))
+			if node.Level == 1 {
+				l.report(RuleHeadingH1, line, "H1 in body; the template already renders the title as H1")
+			} else if node.Level > prevLevel+1 {
+				l.report(RuleHeadingSkip, line, "heading level skipped from H%d to H%d", prevLevel, node.Level)
+			}
+			prevLevel = node.Level
+		case *ast.Image:
+			if len(bytes.TrimSpace(nodeText(node, src.Body))) == 0 {
+				l.report(RuleImageAlt, lineOf(nodeOffset(node)), "image %q has no alt text", node.Destination)
+			}
+		case *ast.HTMLBlock, *ast.RawHTML:
+			l.checkRawImages(node, src.Body, lineOf)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// checkRawImages reports raw HTML <img> tags without an alt attribute.
+func (l *linter) checkRawImages(n ast.Node, source []byte, lineOf func(int) int) {
+	var segs *text.Segments
+	switch node := n.(type) {
+	case *ast.HTMLBlock:
+		segs = node.Lines()
+	case *ast.RawHTML:
+		segs = node.Segments
+	}
+	for i := 0; i < segs.Len(); i++ {
+		seg := segs.At(i)
+		for _, loc := range rawImgTag.FindAllIndex(seg.Value(source), -1) {
+			tag := seg.Value(source)[loc[0]:loc[1]]
+			if !altAttr.Match(tag) {
+				l.report(RuleImageAlt, lineOf(seg.Start+loc[0]), "<img> tag has no alt attribute")
+			}
+		}
+	}
+}
+
+// nodeOffset returns the byte offset in the body where a node starts.
+func nodeOffset(n ast.Node) int {
+	for c := n; c != nil; c = c.FirstChild() {
+		if c.Type() == ast.TypeBlock && c.Lines().Len() > 0 {
+			return c.Lines().At(0).Start
+		}
+		if t, ok := c.(*ast.Text); ok {
+			return t.Segment.Start
+		}
+	}
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if p.Type() == ast.TypeBlock && p.Lines().Len() > 0 {
+			return p.Lines().At(0).Start
+		}
+	}
+	return 0
+}
+
+// nodeText returns the concatenated text of a node's children (an image's alt text).
+func nodeText(n ast.Node, source []byte) []byte {
+	var buf bytes.Buffer
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			buf.Write(t.Segment.Value(source))
+		} else {
+			buf.Write(nodeText(c, source))
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,122 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/markdown"
+)
+
+func writePost(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writePost(t, dir, "post.md", `---
+title: "A title that is definitely far too long to fit in a search result snippet"
+date: "someday"
+---
+
+# Second title
+
+![](/static/diagram.png)
+
+#### Deep heading
+
+<img src="/static/raw.png">
+`)
+
+	issues, err := File(path, DefaultConfig(), markdown.Options{})
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+
+	want := map[string]int{
+		RuleTitleLength:        2,
+		RuleInvalidDate:        3,
+		RuleDescriptionMissing: 1,
+		RuleHeadingH1:          6,
+		RuleHeadingSkip:        10,
+	}
+	got := make(map[string]int)
+	imageAlt := 0
+	for _, issue := range issues {
+		if issue.Rule == RuleImageAlt {
+			imageAlt++
+			continue
+		}
+		got[issue.Rule] = issue.Line
+	}
+	for rule, line := range want {
+		if got[rule] != line {
+			t.Errorf("rule %s reported at line %d, want %d (issues: %v)", rule, got[rule], line, issues)
+		}
+	}
+	if imageAlt != 2 {
+		t.Errorf("image-alt issues = %d, want 2 (markdown and raw HTML)", imageAlt)
+	}
+}
+
+func TestFile_EmptyPostAndFrontmatterError(t *testing.T) {
+	dir := t.TempDir()
+	empty := writePost(t, dir, "empty.md", "---\ntitle: Empty\ndescription: \"A description that is long enough to pass the minimum length.\"\n---\n\n")
+	broken := writePost(t, dir, "broken.md", "---\ntitle: [broken\n---\n\nBody.")
+
+	issues, err := File(empty, DefaultConfig(), markdown.Options{})
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Rule != RuleEmptyPost {
+		t.Errorf("File(empty) = %v, want a single empty-post issue", issues)
+	}
+
+	issues, err = File(broken, DefaultConfig(), markdown.Options{})
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Rule != RuleFrontmatter || issues[0].Line != 2 {
+		t.Errorf("File(broken) = %v, want a frontmatter issue at line 2", issues)
+	}
+}
+
+func TestIssue_String(t *testing.T) {
+	issue := Issue{File: "content/blog/post.md", Line: 4, Rule: RuleImageAlt, Message: "no alt"}
+	if got, want := issue.String(), "content/blog/post.md:4: image-alt: no alt"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lint.yaml")
+	content := "rules:\n  title-length:\n    max: 70\n  image-alt:\n    enabled: false\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := cfg.Rules[RuleTitleLength]; got.Max != 70 || !got.Enabled || got.Severity != audit.SeverityWarning {
+		t.Errorf("title-length = %+v, want max 70 with defaults kept", got)
+	}
+	if cfg.Rules[RuleImageAlt].Enabled {
+		t.Error("image-alt enabled = true, want false")
+	}
+
+	if err := os.WriteFile(path, []byte("rules:\n  no-such-rule:\n    enabled: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig() expected error for unknown rule, got nil")
+	}
+}
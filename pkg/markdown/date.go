@@ -0,0 +1,50 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDate is returned when a frontmatter date matches none of the supported layouts.
+var ErrInvalidDate = errors.New("invalid date")
+
+// dateLayouts lists the accepted frontmatter date layouts, tried in order.
+// Layouts without a zone are interpreted in the location passed to ParseDate.
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"02.01.2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// ParseDate parses a frontmatter date using the supported layouts.
+// An empty value returns zero time without error. A nil loc means UTC.
+func ParseDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w %q", ErrInvalidDate, value)
+}
@@ -0,0 +1,204 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter formats, detected from the opening delimiter.
+const (
+	FormatYAML = "yaml" // --- ... ---
+	FormatTOML = "toml" // +++ ... +++
+	FormatJSON = "json" // { ... }
+)
+
+// FrontmatterError reports invalid frontmatter with its line in the source file.
+type FrontmatterError struct {
+	Format string
+	Line   int
+	Err    error
+}
+
+func (e *FrontmatterError) Error() string {
+	return fmt.Sprintf("line %d: invalid %s frontmatter: %v", e.Line, e.Format, e.Err)
+}
+
+func (e *FrontmatterError) Unwrap() error {
+	return e.Err
+}
+
+// yamlErrorLine extracts the line number from yaml.v3 error messages.
+var yamlErrorLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// splitFrontmatter separates the frontmatter block from the markdown body.
+// Returns an empty format when the file has no frontmatter. The returned line
+// is where the frontmatter content starts in the file.
+func splitFrontmatter(data []byte) (format string, front, body []byte, line int, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	if len(data) > 0 && data[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return FormatJSON, nil, nil, 1, &FrontmatterError{Format: FormatJSON, Line: jsonErrorLine(data, err), Err: err}
+		}
+		return FormatJSON, raw, data[dec.InputOffset():], 1, nil
+	}
+
+	first, rest, _ := bytes.Cut(data, []byte("\n"))
+	var delim string
+	switch string(bytes.TrimSpace(first)) {
+	case "---":
+		format, delim = FormatYAML, "---"
+	case "+++":
+		format, delim = FormatTOML, "+++"
+	default:
+		return "", nil, data, 1, nil
+	}
+
+	for offset := 0; offset < len(rest); {
+		l, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		next := min(offset+len(l)+1, len(rest))
+		if string(bytes.TrimSpace(l)) == delim {
+			return format, rest[:offset], rest[next:], 2, nil
+		}
+		offset = next
+	}
+
+	return format, nil, nil, 1, &FrontmatterError{Format: format, Line: 1, Err: fmt.Errorf("missing closing %q", delim)}
+}
+
+// decodeFrontmatter decodes a frontmatter block into a generic map.
+// Errors carry the line in the source file, given the line where the block starts.
+func decodeFrontmatter(format string, front []byte, line int) (map[string]any, error) {
+	data := make(map[string]any)
+
+	switch format {
+	case "":
+		return data, nil
+
+	case FormatYAML:
+		var doc yaml.Node
+		if err := yaml.Unmarshal(front, &doc); err != nil {
+			errLine, msg := line, err.Error()
+			if m := yamlErrorLine.FindStringSubmatch(msg); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				errLine, msg = line+n-1, m[2]
+			}
+			return nil, &FrontmatterError{Format: format, Line: errLine, Err: errors.New(msg)}
+		}
+		if len(doc.Content) == 0 {
+			return data, nil
+		}
+		v, err := yamlValue(doc.Content[0])
+		if err != nil {
+			return nil, &FrontmatterError{Format: format, Line: line + doc.Content[0].Line - 1, Err: err}
+		}
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, &FrontmatterError{Format: format, Line: line, Err: errors.New("expected a mapping")}
+		}
+		return m, nil
+
+	case FormatTOML:
+		if _, err := toml.Decode(string(front), &data); err != nil {
+			var pe toml.ParseError
+			if errors.As(err, &pe) {
+				return nil, &FrontmatterError{Format: format, Line: line + pe.Position.Line - 1, Err: errors.New(pe.Message)}
+			}
+			return nil, &FrontmatterError{Format: format, Line: line, Err: err}
+		}
+		for k, v := range data {
+			data[k] = tomlValue(v)
+		}
+		return data, nil
+
+	case FormatJSON:
+		if err := json.Unmarshal(front, &data); err != nil {
+			return nil, &FrontmatterError{Format: format, Line: jsonErrorLine(front, err) + line - 1, Err: err}
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("unsupported frontmatter format %q", format)
+}
+
+// yamlValue converts a YAML node to a generic value.
+// Timestamps are kept as their source text so dates without a zone can be
+// interpreted in the site location instead of UTC.
+func yamlValue(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := yamlValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[n.Content[i].Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := yamlValue(c)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case yaml.ScalarNode:
+		if n.ShortTag() == "!!timestamp" {
+			return n.Value, nil
+		}
+	}
+	var v any
+	err := n.Decode(&v)
+	return v, err
+}
+
+// tomlValue converts TOML local dates and datetimes (no offset) to strings,
+// so they are interpreted in the site location like other zone-less dates.
+func tomlValue(v any) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05")
+	}
+	return t
+}
+
+// jsonErrorLine returns the 1-based line of a JSON decoding error within data.
+func jsonErrorLine(data []byte, err error) int {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return 1
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -2,23 +2,32 @@ package markdown
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/yuin/goldmark"
-	meta "github.com/yuin/goldmark-meta"
-	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
 )
 
 // Post represents a parsed markdown file with metadata and rendered HTML content.
 type Post struct {
 	Meta    PostMeta
 	Content string
+	// Raw is the markdown source of the body, without frontmatter.
+	Raw string
+
+	// Violations lists raw HTML removed by the sanitizer when Options.RawHTML is set.
+	Violations []Violation
 }
 
 // PostMeta contains frontmatter metadata from a markdown file.
@@ -30,102 +39,157 @@ type PostMeta struct {
 	Published   bool           `yaml:"published"`
 	Slug        string         `yaml:"slug"`
 	Extra       map[string]any `yaml:"-"`
+
+	// Language is the content language, from the "language" field, the
+	// filename suffix (post.pl.md) or Options.DefaultLanguage.
+	Language string `yaml:"language"`
+	// TranslationKey links translations of the same post. Defaults to the
+	// filename without the language suffix.
+	TranslationKey string `yaml:"translation_key"`
+
+	// Time is Date parsed in the configured location. Zero when Date is empty.
+	Time time.Time `yaml:"-"`
+}
+
+// Options configures how markdown files are parsed.
+type Options struct {
+	// Location is used for dates without an explicit zone. Defaults to UTC.
+	Location *time.Location
+
+	// DefaultLanguage is assigned to posts without a language suffix.
+	DefaultLanguage string
+	// Languages lists the filename suffixes recognized as translations.
+	Languages []string
+
+	// RawHTML enables raw HTML in markdown, filtered through the given policy.
+	// When nil, raw HTML is omitted from the output.
+	RawHTML *Policy
 }
 
 // coreFields defines the standard frontmatter fields.
 // Any field not in this set goes into Extra.
 var coreFields = map[string]bool{
-	"title":       true,
-	"date":        true,
-	"description": true,
-	"author":      true,
-	"published":   true,
-	"slug":        true,
+	"title":           true,
+	"date":            true,
+	"description":     true,
+	"author":          true,
+	"published":       true,
+	"slug":            true,
+	"language":        true,
+	"translation_key": true,
 }
 
-// FormattedDate formats the post date to a human-readable format.
-// Supports RFC3339 and "2006-01-02" formats. Returns raw date on parse failure.
-func (p PostMeta) FormattedDate() string {
-	if p.Date == "" {
-		return ""
-	}
-	t, err := time.Parse(time.RFC3339, p.Date)
-	if err != nil {
-		t, err = time.Parse("2006-01-02", p.Date)
-		if err != nil {
-			return p.Date
-		}
+// ParseDir reads all markdown files from a directory and returns parsed posts.
+// Posts are sorted by date (newest first). Non-markdown files are ignored.
+// Files that fail to parse are reported together in the returned error,
+// alongside the posts that parsed successfully. Parsing stops with ctx.Err()
+// when ctx is canceled. File names in errors include dir.
+func ParseDir(ctx context.Context, dir string, opts Options) ([]Post, error) {
+	dir = filepath.Clean(dir)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("reading directory %s: %w", dir, err)
 	}
-	return t.Format("January 02, 2006")
+	return parseFS(ctx, os.DirFS(dir), ".", dir, opts)
 }
 
-// ParseDate returns the parsed time.Time for sorting purposes.
-// Returns zero time on parse failure.
-func (p PostMeta) ParseDate() time.Time {
-	t, err := time.Parse(time.RFC3339, p.Date)
-	if err != nil {
-		t, _ = time.Parse("2006-01-02", p.Date)
-	}
-	return t
+// ParseFS is like ParseDir but reads dir from fsys. Error messages and
+// Source paths use names relative to fsys.
+func ParseFS(ctx context.Context, fsys fs.FS, dir string, opts Options) ([]Post, error) {
+	return parseFS(ctx, fsys, dir, "", opts)
 }
 
-// ParseDir reads all markdown files from a directory and returns parsed posts.
-// Posts are sorted by date (newest first). Non-markdown files are ignored.
-func ParseDir(dir string) ([]Post, error) {
+// parseFS parses the markdown files of dir in fsys. File names in errors
+// are prefixed with root, the location of fsys on disk, when set.
+func parseFS(ctx context.Context, fsys fs.FS, dir, root string, opts Options) ([]Post, error) {
 	slog.Debug("parsing markdown directory", "dir", dir)
 
-	entries, err := os.ReadDir(dir)
+	entries, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, fmt.Errorf("reading directory %s: %w", dir, err)
 	}
 
 	var posts []Post
+	var errs []error
 	for _, entry := range entries {
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
 			continue
 		}
-		path := filepath.Join(dir, entry.Name())
-		post, err := ParseFile(path)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := path.Join(dir, entry.Name())
+		post, err := ParseFileFS(fsys, name, opts)
 		if err != nil {
-			slog.Warn("skipping file", "path", path, "error", err)
+			if root != "" {
+				name = filepath.Join(root, filepath.FromSlash(name))
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
 			continue
 		}
 		posts = append(posts, *post)
 	}
 
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].Meta.ParseDate().After(posts[j].Meta.ParseDate())
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Meta.Time.After(posts[j].Meta.Time)
 	})
 
-	slog.Debug("parsed markdown directory", "dir", dir, "count", len(posts))
-	return posts, nil
+	slog.Debug("parsed markdown directory", "dir", dir, "count", len(posts), "errors", len(errs))
+	return posts, errors.Join(errs...)
 }
 
 // ParseFile reads a markdown file and extracts frontmatter and rendered HTML content.
-func ParseFile(path string) (*Post, error) {
-	data, err := os.ReadFile(path)
+// The markdown body is kept in Post.Raw. YAML (---), TOML (+++) and JSON ({ ... }) frontmatter are supported.
+func ParseFile(path string, opts Options) (*Post, error) {
+	src, err := ReadSource(path)
 	if err != nil {
-		return nil, fmt.Errorf("reading file: %w", err)
+		return nil, err
 	}
+	return parseSource(src, opts)
+}
 
-	md := goldmark.New(goldmark.WithExtensions(meta.Meta))
-	var buf bytes.Buffer
-	ctx := parser.NewContext()
+// ParseFileFS is like ParseFile but reads name from fsys.
+func ParseFileFS(fsys fs.FS, name string, opts Options) (*Post, error) {
+	src, err := ReadSourceFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return parseSource(src, opts)
+}
 
-	if err := md.Convert(data, &buf, parser.WithContext(ctx)); err != nil {
+// parseSource renders the body of src and extracts its metadata.
+func parseSource(src *Source, opts Options) (*Post, error) {
+	var buf bytes.Buffer
+	if err := newRenderer(opts).Convert(src.Body, &buf); err != nil {
 		return nil, fmt.Errorf("parsing markdown: %w", err)
 	}
+	content := buf.Bytes()
+	var violations []Violation
+	if opts.RawHTML != nil {
+		content, violations = Sanitize(content, *opts.RawHTML)
+	}
 
-	metaData := meta.Get(ctx)
-	postMeta := extractMeta(metaData, path)
+	postMeta, err := src.Meta(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	slog.Debug("parsed file", "path", path, "title", postMeta.Title, "slug", postMeta.Slug)
+	slog.Debug("parsed file", "path", src.Path, "title", postMeta.Title, "slug", postMeta.Slug)
 
-	return &Post{Meta: postMeta, Content: buf.String()}, nil
+	return &Post{Meta: postMeta, Content: string(content), Raw: string(src.Body), Violations: violations}, nil
+}
+
+// newRenderer returns the goldmark renderer for opts. Raw HTML is only
+// passed through when a sanitizer policy is configured.
+func newRenderer(opts Options) goldmark.Markdown {
+	if opts.RawHTML == nil {
+		return goldmark.New()
+	}
+	return goldmark.New(goldmark.WithRendererOptions(html.WithUnsafe()))
 }
 
 // extractMeta converts raw metadata map to PostMeta struct.
-func extractMeta(data map[string]any, path string) PostMeta {
+// Returns an error if the date is present but cannot be parsed.
+func extractMeta(data map[string]any, path string, opts Options) (PostMeta, error) {
 	pm := PostMeta{
 		Extra: make(map[string]any),
 	}
@@ -147,14 +211,25 @@ func extractMeta(data map[string]any, path string) PostMeta {
 	if v, ok := data["slug"].(string); ok {
 		pm.Slug = v
 	}
+	if v, ok := data["language"].(string); ok {
+		pm.Language = v
+	}
+	if v, ok := data["translation_key"].(string); ok {
+		pm.TranslationKey = v
+	}
 
 	// Handle date - can be string or time.Time depending on YAML parsing
 	switch v := data["date"].(type) {
 	case string:
 		pm.Date = v
 	case time.Time:
-		pm.Date = v.Format("2006-01-02")
+		pm.Date = v.Format(time.RFC3339)
 	}
+	t, err := ParseDate(pm.Date, opts.Location)
+	if err != nil {
+		return pm, err
+	}
+	pm.Time = t
 
 	// Collect extra fields
 	for key, value := range data {
@@ -163,12 +238,22 @@ func extractMeta(data map[string]any, path string) PostMeta {
 		}
 	}
 
-	// Generate slug from filename if not specified
+	// Derive language, translation key and slug from the filename if not specified
+	base, lang := splitLanguage(slugFromPath(path), opts.Languages)
+	if pm.Language == "" {
+		pm.Language = lang
+	}
+	if pm.Language == "" {
+		pm.Language = opts.DefaultLanguage
+	}
+	if pm.TranslationKey == "" {
+		pm.TranslationKey = base
+	}
 	if pm.Slug == "" {
-		pm.Slug = slugFromPath(path)
+		pm.Slug = base
 	}
 
-	return pm
+	return pm, nil
 }
 
 // slugFromPath generates a slug from a file path.
@@ -176,3 +261,13 @@ func slugFromPath(path string) string {
 	filename := filepath.Base(path)
 	return strings.TrimSuffix(filename, ".md")
 }
+
+// splitLanguage splits a known language suffix off a file stem ("post.pl" -> "post", "pl").
+// Stems without a recognized suffix are returned unchanged with an empty language.
+func splitLanguage(stem string, languages []string) (string, string) {
+	ext := filepath.Ext(stem)
+	if ext == "" || !slices.Contains(languages, ext[1:]) {
+		return stem, ""
+	}
+	return strings.TrimSuffix(stem, ext), ext[1:]
+}
@@ -1,41 +1,78 @@
 package markdown
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
 
-func TestPostMeta_FormattedDate(t *testing.T) {
 	tests := []struct {
-		name     string
-		date     string
-		expected string
+		name    string
+		date    string
+		loc     *time.Location
+		want    time.Time
+		wantErr bool
 	}{
 		{
-			name:     "RFC3339 format",
-			date:     "2026-01-15T10:30:00Z",
-			expected: "January 15, 2026",
+			name: "RFC3339 format keeps its zone",
+			date: "2026-01-15T10:30:00Z",
+			loc:  warsaw,
+			want: time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "simple date format uses location",
+			date: "2026-01-15",
+			loc:  warsaw,
+			want: time.Date(2026, 1, 15, 0, 0, 0, 0, warsaw),
 		},
 		{
-			name:     "simple date format",
-			date:     "2026-01-15",
-			expected: "January 15, 2026",
+			name: "date and time without zone",
+			date: "2026-01-15 08:15",
+			loc:  nil,
+			want: time.Date(2026, 1, 15, 8, 15, 0, 0, time.UTC),
 		},
 		{
-			name:     "invalid date returns raw",
-			date:     "not-a-date",
-			expected: "not-a-date",
+			name: "long English format",
+			date: "January 15, 2026",
+			loc:  nil,
+			want: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
 		},
 		{
-			name:     "empty date returns empty",
-			date:     "",
-			expected: "",
+			name: "empty date returns zero time",
+			date: "",
+			want: time.Time{},
+		},
+		{
+			name:    "invalid date returns error",
+			date:    "not-a-date",
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pm := PostMeta{Date: tt.date}
-			got := pm.FormattedDate()
-			if got != tt.expected {
-				t.Errorf("FormattedDate() = %q, want %q", got, tt.expected)
+			got, err := ParseDate(tt.date, tt.loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDate) {
+					t.Errorf("ParseDate() error = %v, want ErrInvalidDate", err)
+				}
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -140,6 +177,41 @@ func TestParseFile(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:    "toml frontmatter",
+			file:    "testdata/toml_post.md",
+			wantErr: false,
+			checkPost: func(t *testing.T, post *Post) {
+				if post.Meta.Title != "TOML Post" {
+					t.Errorf("Title = %q, want %q", post.Meta.Title, "TOML Post")
+				}
+				if post.Meta.Date != "2026-01-18" {
+					t.Errorf("Date = %q, want %q", post.Meta.Date, "2026-01-18")
+				}
+				if !post.Meta.Published {
+					t.Error("Published = false, want true")
+				}
+				if _, ok := post.Meta.Extra["tags"]; !ok {
+					t.Error("Extra should contain 'tags'")
+				}
+			},
+		},
+		{
+			name:    "json frontmatter",
+			file:    "testdata/json_post.md",
+			wantErr: false,
+			checkPost: func(t *testing.T, post *Post) {
+				if post.Meta.Title != "JSON Post" {
+					t.Errorf("Title = %q, want %q", post.Meta.Title, "JSON Post")
+				}
+				if post.Meta.Author != "Tool Output" {
+					t.Errorf("Author = %q, want %q", post.Meta.Author, "Tool Output")
+				}
+				if !strings.Contains(post.Content, "Generated by tooling.") {
+					t.Errorf("Content = %q, want body without frontmatter", post.Content)
+				}
+			},
+		},
 		{
 			name:      "non-existent file returns error",
 			file:      "testdata/does_not_exist.md",
@@ -150,7 +222,7 @@ func TestParseFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			post, err := ParseFile(tt.file)
+			post, err := ParseFile(tt.file, Options{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseFile() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -170,10 +242,16 @@ func TestParseDir(t *testing.T) {
 		wantCount int
 	}{
 		{
-			name:      "valid directory with posts",
+			name:      "directory with an invalid post",
 			dir:       "testdata",
-			wantErr:   false,
-			wantCount: 4,
+			wantErr:   true,
+			wantCount: 5,
+		},
+		{
+			name:      "trailing slash",
+			dir:       "testdata/",
+			wantErr:   true,
+			wantCount: 5,
 		},
 		{
 			name:      "non-existent directory returns error",
@@ -185,14 +263,146 @@ func TestParseDir(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			posts, err := ParseDir(tt.dir)
+			posts, err := ParseDir(context.Background(), tt.dir, Options{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseDir() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !tt.wantErr && len(posts) != tt.wantCount {
+			if len(posts) != tt.wantCount {
 				t.Errorf("ParseDir() returned %d posts, want %d", len(posts), tt.wantCount)
 			}
 		})
 	}
 }
+
+func TestParseDir_InvalidDate(t *testing.T) {
+	dir := t.TempDir()
+	good := "---\ntitle: Good\ndate: \"2026-01-15\"\n---\n\nBody."
+	bad := "---\ntitle: Bad\ndate: \"15th of Never\"\n---\n\nBody."
+	if err := os.WriteFile(filepath.Join(dir, "good.md"), []byte(good), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.md"), []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := ParseDir(context.Background(), dir, Options{})
+	if !errors.Is(err, ErrInvalidDate) {
+		t.Fatalf("ParseDir() error = %v, want ErrInvalidDate", err)
+	}
+	if len(posts) != 1 || posts[0].Meta.Title != "Good" {
+		t.Errorf("ParseDir() posts = %+v, want only the valid post", posts)
+	}
+	if want := filepath.Join(dir, "bad.md") + ":"; !strings.Contains(err.Error(), want) {
+		t.Errorf("ParseDir() error = %v, want it to name %s", err, want)
+	}
+}
+
+func TestSplitLanguage(t *testing.T) {
+	languages := []string{"en", "pl"}
+
+	tests := []struct {
+		name     string
+		stem     string
+		wantBase string
+		wantLang string
+	}{
+		{name: "translation suffix", stem: "my-post.pl", wantBase: "my-post", wantLang: "pl"},
+		{name: "no suffix", stem: "my-post", wantBase: "my-post", wantLang: ""},
+		{name: "unknown suffix is part of the slug", stem: "my.special.post", wantBase: "my.special.post", wantLang: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, lang := splitLanguage(tt.stem, languages)
+			if base != tt.wantBase || lang != tt.wantLang {
+				t.Errorf("splitLanguage(%q) = %q, %q, want %q, %q", tt.stem, base, lang, tt.wantBase, tt.wantLang)
+			}
+		})
+	}
+}
+
+func TestParseFile_Translation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.pl.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: Cześć\n---\n\nTreść."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := ParseFile(path, Options{DefaultLanguage: "en", Languages: []string{"en", "pl"}})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if post.Meta.Language != "pl" {
+		t.Errorf("Language = %q, want pl", post.Meta.Language)
+	}
+	if post.Meta.Slug != "hello" {
+		t.Errorf("Slug = %q, want hello", post.Meta.Slug)
+	}
+	if post.Meta.TranslationKey != "hello" {
+		t.Errorf("TranslationKey = %q, want hello", post.Meta.TranslationKey)
+	}
+}
+
+func TestParseFile_FrontmatterErrorLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int
+	}{
+		{
+			name:     "yaml",
+			content:  "---\ntitle: ok\ndate: \"not closed\n---\n\nBody.",
+			wantLine: 3,
+		},
+		{
+			name:     "toml",
+			content:  "+++\ntitle = \"ok\"\n\ndate = = 1\n+++\n\nBody.",
+			wantLine: 4,
+		},
+		{
+			name:     "json",
+			content:  "{\n  \"title\": \"ok\",\n  \"date\": 2026-01-01\n}\n\nBody.",
+			wantLine: 3,
+		},
+		{
+			name:     "unclosed yaml",
+			content:  "---\ntitle: ok\n\nBody.",
+			wantLine: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := ParseFile(path, Options{})
+			var fmErr *FrontmatterError
+			if !errors.As(err, &fmErr) {
+				t.Fatalf("ParseFile() error = %v, want FrontmatterError", err)
+			}
+			if fmErr.Line != tt.wantLine {
+				t.Errorf("FrontmatterError.Line = %d, want %d (%v)", fmErr.Line, tt.wantLine, err)
+			}
+		})
+	}
+}
+
+func TestParseFile_KeepsRawSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "raw.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: Raw\n---\n\n## Heading\n\nSome *emphasis*.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := ParseFile(path, Options{})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if want := "\n## Heading\n\nSome *emphasis*.\n"; post.Raw != want {
+		t.Errorf("Raw = %q, want %q", post.Raw, want)
+	}
+}
@@ -0,0 +1,212 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Policy is an allowlist of HTML tags and their attributes.
+// The "*" key lists attributes allowed on every tag.
+type Policy struct {
+	Tags map[string][]string
+}
+
+// Violation describes a tag or attribute removed by the sanitizer.
+type Violation struct {
+	Tag    string
+	Attr   string
+	Reason string
+}
+
+func (v Violation) String() string {
+	if v.Attr != "" {
+		return fmt.Sprintf("<%s %s>: %s", v.Tag, v.Attr, v.Reason)
+	}
+	return fmt.Sprintf("<%s>: %s", v.Tag, v.Reason)
+}
+
+// droppedWithContent lists tags removed together with everything inside them.
+// Raw text elements such as xmp and title are among them: the tokenizer
+// returns their markup as text, which must not be written back as HTML.
+var droppedWithContent = []string{
+	"script", "style", "iframe", "object", "embed", "template", "noscript", "textarea", "select",
+	"xmp", "title", "noembed", "noframes", "plaintext",
+}
+
+// urlAttrs lists attributes whose values are URLs and must use a safe scheme.
+var urlAttrs = []string{"href", "src", "cite", "poster", "srcset"}
+
+// DefaultPolicy returns the tags produced by markdown rendering plus a few
+// common authoring tags such as <details>, <kbd> and <figure>.
+func DefaultPolicy() Policy {
+	return Policy{Tags: map[string][]string{
+		"*":          {"id", "class", "title", "lang", "dir"},
+		"a":          {"href", "rel", "target"},
+		"abbr":       {},
+		"blockquote": {"cite"},
+		"br":         {},
+		"code":       {},
+		"dd":         {},
+		"del":        {},
+		"details":    {"open"},
+		"dl":         {},
+		"dt":         {},
+		"em":         {},
+		"figcaption": {},
+		"figure":     {},
+		"h1":         {},
+		"h2":         {},
+		"h3":         {},
+		"h4":         {},
+		"h5":         {},
+		"h6":         {},
+		"hr":         {},
+		"img":        {"src", "alt", "width", "height", "loading"},
+		"input":      {"type", "checked", "disabled"},
+		"kbd":        {},
+		"li":         {},
+		"mark":       {},
+		"ol":         {"start"},
+		"p":          {},
+		"pre":        {},
+		"s":          {},
+		"samp":       {},
+		"small":      {},
+		"strong":     {},
+		"sub":        {},
+		"summary":    {},
+		"sup":        {},
+		"table":      {},
+		"tbody":      {},
+		"td":         {"align"},
+		"tfoot":      {},
+		"th":         {"align", "scope"},
+		"thead":      {},
+		"tr":         {},
+		"ul":         {},
+	}}
+}
+
+// Allow returns a copy of the policy with extra tags and attributes allowed.
+func (p Policy) Allow(tags map[string][]string) Policy {
+	merged := make(map[string][]string, len(p.Tags)+len(tags))
+	for tag, attrs := range p.Tags {
+		merged[tag] = slices.Clone(attrs)
+	}
+	for tag, attrs := range tags {
+		tag = strings.ToLower(tag)
+		for _, a := range attrs {
+			if a = strings.ToLower(a); !slices.Contains(merged[tag], a) {
+				merged[tag] = append(merged[tag], a)
+			}
+		}
+		if _, ok := merged[tag]; !ok {
+			merged[tag] = []string{}
+		}
+	}
+	return Policy{Tags: merged}
+}
+
+// allowsAttr reports whether attr is allowed on tag.
+func (p Policy) allowsAttr(tag, attr string) bool {
+	return slices.Contains(p.Tags[tag], attr) || slices.Contains(p.Tags["*"], attr)
+}
+
+// Sanitize filters HTML through the policy. Disallowed tags are removed but
+// their text is kept, except for script-like tags which are removed entirely.
+// Disallowed attributes and unsafe URLs are removed. Comments are dropped.
+func Sanitize(src []byte, policy Policy) ([]byte, []Violation) {
+	var out bytes.Buffer
+	var violations []Violation
+	z := html.NewTokenizer(bytes.NewReader(src))
+	skipDepth := 0
+	var skipTag string
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+
+		if skipDepth > 0 {
+			name, _ := z.TagName()
+			switch {
+			case tt == html.StartTagToken && string(name) == skipTag:
+				skipDepth++
+			case tt == html.EndTagToken && string(name) == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			// Re-escaped rather than copied, so text never turns into markup.
+			out.WriteString(html.EscapeString(string(z.Text())))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			tag := tok.Data
+			if _, ok := policy.Tags[tag]; !ok {
+				if slices.Contains(droppedWithContent, tag) {
+					violations = append(violations, Violation{Tag: tag, Reason: "tag removed with its content"})
+					if tt == html.StartTagToken {
+						skipDepth, skipTag = 1, tag
+					}
+					continue
+				}
+				violations = append(violations, Violation{Tag: tag, Reason: "tag not allowed"})
+				continue
+			}
+
+			kept := tok.Attr[:0]
+			for _, a := range tok.Attr {
+				key := strings.ToLower(a.Key)
+				switch {
+				case !policy.allowsAttr(tag, key):
+					violations = append(violations, Violation{Tag: tag, Attr: key, Reason: "attribute not allowed"})
+				case slices.Contains(urlAttrs, key) && !isSafeURL(a.Val):
+					violations = append(violations, Violation{Tag: tag, Attr: key, Reason: fmt.Sprintf("unsafe URL %q", a.Val)})
+				default:
+					kept = append(kept, a)
+				}
+			}
+			if len(kept) == len(tok.Attr) && len(z.Raw()) > 0 {
+				out.Write(raw)
+				continue
+			}
+			tok.Attr = kept
+			out.WriteString(tok.String())
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if _, ok := policy.Tags[string(name)]; ok {
+				out.Write(raw)
+			}
+
+		case html.CommentToken, html.DoctypeToken:
+			// Dropped silently; neither belongs in post content.
+		}
+	}
+
+	return out.Bytes(), violations
+}
+
+// isSafeURL reports whether a URL is relative or uses an allowed scheme.
+func isSafeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
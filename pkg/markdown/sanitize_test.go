@@ -0,0 +1,158 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		want           string
+		wantViolations int
+	}{
+		{
+			name:  "allowed tags pass through",
+			input: `<details open><summary>More</summary><p>Press <kbd>Ctrl</kbd></p></details>`,
+			want:  `<details open><summary>More</summary><p>Press <kbd>Ctrl</kbd></p></details>`,
+		},
+		{
+			name:           "script removed with content",
+			input:          `<p>a</p><script>alert(1)</script><p>b</p>`,
+			want:           `<p>a</p><p>b</p>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "unknown tag removed but text kept",
+			input:          `<marquee>hello</marquee>`,
+			want:           `hello`,
+			wantViolations: 1,
+		},
+		{
+			name:           "event handler attribute removed",
+			input:          `<figure onclick="x()" class="wide"><figcaption>Cap</figcaption></figure>`,
+			want:           `<figure class="wide"><figcaption>Cap</figcaption></figure>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "javascript URL removed",
+			input:          `<a href="javascript:alert(1)">x</a>`,
+			want:           `<a>x</a>`,
+			wantViolations: 1,
+		},
+		{
+			name:  "relative and https URLs kept",
+			input: `<a href="/blog/">x</a><img src="https://example.com/a.png" alt="a">`,
+			want:  `<a href="/blog/">x</a><img src="https://example.com/a.png" alt="a">`,
+		},
+		{
+			name:  "comments dropped",
+			input: `<p>a<!-- hidden --></p>`,
+			want:  `<p>a</p>`,
+		},
+		{
+			name:           "xmp removed with content",
+			input:          `<xmp><script>alert(1)</script></xmp><p>b</p>`,
+			want:           `<p>b</p>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "title removed with content",
+			input:          `<title><img src=x onerror=alert(1)></title><p>b</p>`,
+			want:           `<p>b</p>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "noembed removed with content",
+			input:          `<noembed><img src=x onerror=alert(1)></noembed><p>b</p>`,
+			want:           `<p>b</p>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "noframes removed with content",
+			input:          `<noframes><script>alert(1)</script></noframes><p>b</p>`,
+			want:           `<p>b</p>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "plaintext removed with the rest of the input",
+			input:          `<p>a</p><plaintext><script>alert(1)</script>`,
+			want:           `<p>a</p>`,
+			wantViolations: 1,
+		},
+		{
+			name:  "escaped text preserved",
+			input: `<p>&lt;script&gt;</p>`,
+			want:  `<p>&lt;script&gt;</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := Sanitize([]byte(tt.input), DefaultPolicy())
+			if string(got) != tt.want {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.want)
+			}
+			if len(violations) != tt.wantViolations {
+				t.Errorf("Sanitize() violations = %v, want %d", violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestSanitize_AllowedRawTextEscaped(t *testing.T) {
+	policy := DefaultPolicy().Allow(map[string][]string{"xmp": nil})
+	got, _ := Sanitize([]byte(`<xmp><script>alert(1)</script></xmp>`), policy)
+	if want := `<xmp>&lt;script&gt;alert(1)&lt;/script&gt;</xmp>`; string(got) != want {
+		t.Errorf("Sanitize() = %q, want %q", got, want)
+	}
+}
+
+func TestPolicyAllow(t *testing.T) {
+	policy := DefaultPolicy().Allow(map[string][]string{"Video": {"SRC", "controls"}})
+
+	got, violations := Sanitize([]byte(`<video src="/a.mp4" controls></video>`), policy)
+	if len(violations) != 0 {
+		t.Errorf("violations = %v, want none", violations)
+	}
+	if string(got) != `<video src="/a.mp4" controls></video>` {
+		t.Errorf("Sanitize() = %q", got)
+	}
+	if _, ok := DefaultPolicy().Tags["video"]; ok {
+		t.Error("Allow() modified the default policy")
+	}
+}
+
+func TestParseFile_RawHTML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.md")
+	content := "---\ntitle: Raw\n---\n\n<details>\n<summary>Hi</summary>\n\nText\n\n</details>\n\n<script>alert(1)</script>\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := ParseFile(path, Options{})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if strings.Contains(post.Content, "<details>") {
+		t.Error("raw HTML rendered without RawHTML option")
+	}
+
+	policy := DefaultPolicy()
+	post, err = ParseFile(path, Options{RawHTML: &policy})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if !strings.Contains(post.Content, "<details>") || !strings.Contains(post.Content, "<summary>Hi</summary>") {
+		t.Errorf("Content = %q, want details kept", post.Content)
+	}
+	if strings.Contains(post.Content, "script") {
+		t.Errorf("Content = %q, want script removed", post.Content)
+	}
+	if len(post.Violations) != 1 {
+		t.Errorf("Violations = %v, want 1", post.Violations)
+	}
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+)
+
+// Source is a markdown file split into decoded frontmatter and raw body.
+// It is used by tools that inspect content without rendering it.
+type Source struct {
+	Path        string
+	Format      string // FormatYAML, FormatTOML, FormatJSON or "" without frontmatter
+	Frontmatter map[string]any
+	Body        []byte
+	// BodyLine is the line in the file where Body starts.
+	BodyLine int
+
+	front     []byte
+	frontLine int
+}
+
+// ReadSource reads a markdown file and decodes its frontmatter.
+func ReadSource(path string) (*Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return NewSource(path, data)
+}
+
+// ReadSourceFS reads a markdown file from fsys and decodes its frontmatter.
+func ReadSourceFS(fsys fs.FS, name string) (*Source, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return NewSource(name, data)
+}
+
+// NewSource decodes the frontmatter of markdown data read from path.
+func NewSource(path string, data []byte) (*Source, error) {
+	format, front, body, line, err := splitFrontmatter(data)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := decodeFrontmatter(format, front, line)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		Path:        path,
+		Format:      format,
+		Frontmatter: fm,
+		Body:        body,
+		BodyLine:    bytes.Count(data[:len(data)-len(body)], []byte("\n")) + 1,
+		front:       front,
+		frontLine:   line,
+	}, nil
+}
+
+// Meta converts the frontmatter to PostMeta.
+// On an invalid date the error is returned along with the remaining fields.
+func (s *Source) Meta(opts Options) (PostMeta, error) {
+	return extractMeta(s.Frontmatter, s.Path, opts)
+}
+
+// FieldLine returns the line in the file where a top-level frontmatter key is set.
+// Returns the line of the opening delimiter if the key is not found.
+func (s *Source) FieldLine(key string) int {
+	re := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(key) + `"?\s*[:=]`)
+	for i, l := range bytes.Split(s.front, []byte("\n")) {
+		if re.Match(l) {
+			return s.frontLine + i
+		}
+	}
+	return max(s.frontLine-1, 1)
+}
@@ -0,0 +1,315 @@
+// Package seo audits the metadata of generated HTML pages: titles, meta
+// descriptions, canonical URLs, Open Graph images and JSON-LD.
+package seo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/website"
+
+	"golang.org/x/net/html"
+)
+
+// Rule names, as used in output.
+const (
+	RuleTitleMissing         = "title-missing"
+	RuleTitleLength          = "title-length"
+	RuleTitleDuplicate       = "title-duplicate"
+	RuleDescriptionMissing   = "description-missing"
+	RuleDescriptionLength    = "description-length"
+	RuleDescriptionDuplicate = "description-duplicate"
+	RuleCanonicalMissing     = "canonical-missing"
+	RuleCanonicalInvalid     = "canonical-invalid"
+	RuleCanonicalMismatch    = "canonical-mismatch"
+	RuleOGImageMissing       = "og-image-missing"
+	RuleJSONLDInvalid        = "jsonld-invalid"
+)
+
+// Length limits, in characters.
+const (
+	MaxTitle       = 60
+	MinDescription = 50
+	MaxDescription = 160
+)
+
+// Issue is a single problem found in a page.
+type Issue struct {
+	Rule     string         `json:"rule"`
+	Severity audit.Severity `json:"severity"`
+	Message  string         `json:"message"`
+}
+
+// Page is the metadata read from one generated page and its issues.
+type Page struct {
+	// Path is the URL path the page is served at, e.g. "/blog/".
+	Path string `json:"path"`
+	// File is the output file, e.g. "blog/index.html".
+	File        string  `json:"file"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Canonical   string  `json:"canonical"`
+	OGImage     string  `json:"og_image"`
+	NoIndex     bool    `json:"noindex"`
+	JSONLD      int     `json:"jsonld"`
+	Issues      []Issue `json:"issues"`
+}
+
+// Report is the result of an audit, with pages sorted by path.
+type Report struct {
+	Pages []Page `json:"pages"`
+}
+
+// Count returns the number of issues with the given severity.
+func (r Report) Count(severity audit.Severity) int {
+	n := 0
+	for _, p := range r.Pages {
+		for _, issue := range p.Issues {
+			if issue.Severity == severity {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Audit reads every HTML page in fsys and checks its metadata against the
+// values the templates derive from site. Files without an <html> tag, such
+// as verification files, are skipped. Duplicate titles and descriptions are
+// only reported between indexed pages.
+func Audit(fsys fs.FS, site website.SiteConfig) (Report, error) {
+	var report Report
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(bytes.ToLower(data), []byte("<html")) {
+			return nil
+		}
+		page, err := auditPage(site, name, data)
+		if err != nil {
+			return fmt.Errorf("auditing %s: %w", name, err)
+		}
+		report.Pages = append(report.Pages, page)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	sort.Slice(report.Pages, func(i, j int) bool { return report.Pages[i].Path < report.Pages[j].Path })
+	reportDuplicates(report.Pages, RuleTitleDuplicate, "title", func(p Page) string { return p.Title })
+	reportDuplicates(report.Pages, RuleDescriptionDuplicate, "description", func(p Page) string { return p.Description })
+	return report, nil
+}
+
+// auditPage extracts the metadata of a page into website.SEO and checks it.
+func auditPage(site website.SiteConfig, name string, data []byte) (Page, error) {
+	seo, ogURL, jsonLD, err := ParsePage(data)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{
+		Path:        website.OutputURLPath(name),
+		File:        name,
+		Title:       seo.Title,
+		Description: seo.Description,
+		Canonical:   seo.Canonical,
+		OGImage:     seo.OGImage,
+		NoIndex:     seo.NoIndex,
+		JSONLD:      len(jsonLD),
+	}
+	add := func(rule string, severity audit.Severity, format string, args ...any) {
+		page.Issues = append(page.Issues, Issue{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch n := utf8.RuneCountInString(seo.Title); {
+	case n == 0:
+		add(RuleTitleMissing, audit.SeverityError, "page has no <title>")
+	case n > MaxTitle:
+		add(RuleTitleLength, audit.SeverityWarning, "title is %d characters, want at most %d", n, MaxTitle)
+	}
+
+	switch n := utf8.RuneCountInString(seo.Description); {
+	case n == 0:
+		add(RuleDescriptionMissing, audit.SeverityError, "page has no meta description")
+	case n < MinDescription || n > MaxDescription:
+		add(RuleDescriptionLength, audit.SeverityWarning, "description is %d characters, want %d-%d", n, MinDescription, MaxDescription)
+	}
+
+	// Posts may point their canonical at another page with a frontmatter
+	// override, so only relative URLs and other hosts are reported.
+	canonical, err := url.Parse(seo.Canonical)
+	switch {
+	case seo.Canonical == "":
+		add(RuleCanonicalMissing, audit.SeverityError, "page has no canonical link")
+	case err != nil || (canonical.Scheme != "http" && canonical.Scheme != "https") || canonical.Host == "":
+		add(RuleCanonicalInvalid, audit.SeverityError, "canonical %s is not an absolute URL", seo.Canonical)
+	case site.URL != "" && canonical.Host != siteHost(site):
+		add(RuleCanonicalMismatch, audit.SeverityWarning, "canonical %s points to another site", seo.Canonical)
+	}
+	if ogURL != "" && seo.Canonical != "" && ogURL != seo.Canonical {
+		add(RuleCanonicalMismatch, audit.SeverityWarning, "og:url %s does not match the canonical %s", ogURL, seo.Canonical)
+	}
+
+	switch {
+	case seo.OGImage == "":
+		add(RuleOGImageMissing, audit.SeverityError, "page has no og:image")
+	case !strings.HasPrefix(seo.OGImage, "http://") && !strings.HasPrefix(seo.OGImage, "https://"):
+		add(RuleOGImageMissing, audit.SeverityError, "og:image %s is not an absolute URL", seo.OGImage)
+	}
+
+	for i, block := range jsonLD {
+		if err := validateJSONLD(block); err != nil {
+			add(RuleJSONLDInvalid, audit.SeverityError, "JSON-LD block %d: %v", i+1, err)
+		}
+	}
+	return page, nil
+}
+
+// ParsePage reads the metadata of a rendered page into the website.SEO fields
+// the templates fill (Title, Description, Canonical, NoIndex, OGType,
+// OGImage, OGImageAlt), and returns og:url and the JSON-LD blocks.
+func ParsePage(data []byte) (seo website.SEO, ogURL string, jsonLD []string, err error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return seo, "", nil, err
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "title":
+				if seo.Title == "" {
+					seo.Title = strings.TrimSpace(text(n))
+				}
+			case "link":
+				if strings.EqualFold(attr(n, "rel"), "canonical") {
+					seo.Canonical = attr(n, "href")
+				}
+			case "meta":
+				content := strings.TrimSpace(attr(n, "content"))
+				switch attr(n, "name") + attr(n, "property") {
+				case "description":
+					seo.Description = content
+				case "robots":
+					seo.NoIndex = strings.Contains(strings.ToLower(content), "noindex")
+				case "og:type":
+					seo.OGType = content
+				case "og:url":
+					ogURL = content
+				case "og:image":
+					seo.OGImage = content
+				case "og:image:alt":
+					seo.OGImageAlt = content
+				}
+			case "script":
+				if strings.EqualFold(attr(n, "type"), "application/ld+json") {
+					jsonLD = append(jsonLD, text(n))
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return seo, ogURL, jsonLD, nil
+}
+
+// validateJSONLD checks that a JSON-LD block is valid JSON with an @context
+// and an @type on every top-level object.
+func validateJSONLD(block string) error {
+	var v any
+	if err := json.Unmarshal([]byte(block), &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	objects, ok := v.([]any)
+	if !ok {
+		objects = []any{v}
+	}
+	for _, o := range objects {
+		obj, ok := o.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected an object, got %T", o)
+		}
+		if _, ok := obj["@context"]; !ok {
+			return fmt.Errorf("missing @context")
+		}
+		if _, ok := obj["@type"]; !ok {
+			if _, ok := obj["@graph"]; !ok {
+				return fmt.Errorf("missing @type")
+			}
+		}
+	}
+	return nil
+}
+
+// reportDuplicates adds an issue to every indexed page sharing a non-empty
+// value with another indexed page.
+func reportDuplicates(pages []Page, rule, what string, value func(Page) string) {
+	byValue := make(map[string][]int)
+	for i, p := range pages {
+		if v := value(p); v != "" && !p.NoIndex {
+			byValue[v] = append(byValue[v], i)
+		}
+	}
+	for _, idx := range byValue {
+		if len(idx) < 2 {
+			continue
+		}
+		for _, i := range idx {
+			var others []string
+			for _, j := range idx {
+				if j != i {
+					others = append(others, pages[j].Path)
+				}
+			}
+			pages[i].Issues = append(pages[i].Issues, Issue{
+				Rule:     rule,
+				Severity: audit.SeverityWarning,
+				Message:  fmt.Sprintf("%s is also used by %s", what, strings.Join(others, ", ")),
+			})
+		}
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func text(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return b.String()
+}
+
+// siteHost returns the host of the site URL.
+func siteHost(site website.SiteConfig) string {
+	u, err := url.Parse(site.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
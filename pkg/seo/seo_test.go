@@ -0,0 +1,128 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/audit"
+	"maciejadamski/pkg/website"
+)
+
+// testPage renders a page head the way the base layout does.
+func testPage(title, description, canonical, ogImage string, jsonLD ...string) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html lang="en"><head><title>` + title + `</title>`)
+	b.WriteString(`<meta name="description" content="` + description + `">`)
+	b.WriteString(`<meta name="robots" content="index, follow">`)
+	if canonical != "" {
+		b.WriteString(`<link rel="canonical" href="` + canonical + `">`)
+		b.WriteString(`<meta property="og:url" content="` + canonical + `">`)
+	}
+	b.WriteString(`<meta property="og:image" content="` + ogImage + `">`)
+	for _, block := range jsonLD {
+		b.WriteString(`<script type="application/ld+json">` + block + `</script>`)
+	}
+	b.WriteString(`</head><body></body></html>`)
+	return b.String()
+}
+
+const validDescription = "A description that is long enough to be shown in search results."
+
+func rules(page Page) []string {
+	var got []string
+	for _, issue := range page.Issues {
+		got = append(got, issue.Rule)
+	}
+	return got
+}
+
+func TestAudit(t *testing.T) {
+	site := website.SiteConfig{URL: "https://example.com"}
+	image := "https://example.com/og.png"
+	fsys := fstest.MapFS{
+		"index.html": {Data: []byte(testPage("Home", validDescription, "https://example.com/", image,
+			`{"@context":"https://schema.org","@type":"WebSite"}`))},
+		"blog/index.html": {Data: []byte(testPage(strings.Repeat("T", 61), "Too short", "https://example.com/blog/", ""))},
+		"blog/a/index.html": {Data: []byte(testPage("Post", validDescription, "/blog/a/", "/og.png",
+			`{"@context":"https://schema.org","@type":"BlogPosting",}`, `{"@type":"Person"}`))},
+		"blog/c/index.html": {Data: []byte(testPage("Republished", "A post first published on another site, with its canonical there.", "https://example.org/original/", image))},
+		"blog/d/index.html": {Data: []byte(testPage("Moved", "A post whose canonical override points at another page of this site.", "https://example.com/blog/c/", image))},
+		"blog/b/index.html": {Data: []byte(testPage("", "", "", image))},
+		"about.html": {Data: []byte(`<html><head><title>Post</title><meta name="robots" content="noindex">` +
+			`<meta name="description" content="` + validDescription + `"><link rel="canonical" href="https://example.com/about.html">` +
+			`<meta property="og:image" content="` + image + `"></head></html>`)},
+		"google123.html": {Data: []byte("google-site-verification: google123.html")},
+	}
+
+	report, err := Audit(fsys, site)
+	if err != nil {
+		t.Fatalf("Audit() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"/":           {RuleDescriptionDuplicate},
+		"/about.html": nil,
+		"/blog/":      {RuleTitleLength, RuleDescriptionLength, RuleOGImageMissing},
+		"/blog/a/":    {RuleCanonicalInvalid, RuleOGImageMissing, RuleJSONLDInvalid, RuleJSONLDInvalid, RuleDescriptionDuplicate},
+		"/blog/b/":    {RuleTitleMissing, RuleDescriptionMissing, RuleCanonicalMissing},
+		"/blog/c/":    {RuleCanonicalMismatch},
+		"/blog/d/":    nil,
+	}
+	if len(report.Pages) != len(want) {
+		t.Fatalf("Audit() pages = %d, want %d", len(report.Pages), len(want))
+	}
+	for _, page := range report.Pages {
+		if got := rules(page); strings.Join(got, ",") != strings.Join(want[page.Path], ",") {
+			t.Errorf("%s rules = %v, want %v\n%v", page.Path, got, want[page.Path], page.Issues)
+		}
+	}
+
+	if got := report.Count(audit.SeverityError); got != 8 {
+		t.Errorf("Count(error) = %d, want 8", got)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	page := `<html><head><title> A &amp; B </title><meta name="description" content="Desc">
+<meta name="robots" content="noindex, nofollow"><link rel="canonical" href="https://example.com/a/">
+<meta property="og:type" content="article"><meta property="og:url" content="https://example.com/a/">
+<meta property="og:image" content="https://example.com/a.png"><meta property="og:image:alt" content="Alt">
+<script type="application/ld+json">{"@context":"https://schema.org"}</script></head></html>`
+
+	seo, ogURL, jsonLD, err := ParsePage([]byte(page))
+	if err != nil {
+		t.Fatalf("ParsePage() error = %v", err)
+	}
+	want := website.SEO{
+		Title: "A & B", Description: "Desc", Canonical: "https://example.com/a/", NoIndex: true,
+		OGType: "article", OGImage: "https://example.com/a.png", OGImageAlt: "Alt",
+	}
+	if seo.Title != want.Title || seo.Description != want.Description || seo.Canonical != want.Canonical ||
+		seo.NoIndex != want.NoIndex || seo.OGType != want.OGType || seo.OGImage != want.OGImage || seo.OGImageAlt != want.OGImageAlt {
+		t.Errorf("ParsePage() seo = %+v, want %+v", seo, want)
+	}
+	if ogURL != "https://example.com/a/" || len(jsonLD) != 1 {
+		t.Errorf("ParsePage() og:url = %q, jsonLD = %v", ogURL, jsonLD)
+	}
+}
+
+func TestValidateJSONLD(t *testing.T) {
+	tests := []struct {
+		block   string
+		wantErr bool
+	}{
+		{`{"@context":"https://schema.org","@type":"Person"}`, false},
+		{`[{"@context":"https://schema.org","@type":"Person"}]`, false},
+		{`{"@context":"https://schema.org","@graph":[]}`, false},
+		{`{"@type":"Person"}`, true},
+		{`{"@context":"https://schema.org"}`, true},
+		{`"text"`, true},
+		{`{"@context":`, true},
+	}
+	for _, tt := range tests {
+		if err := validateJSONLD(tt.block); (err != nil) != tt.wantErr {
+			t.Errorf("validateJSONLD(%s) error = %v, wantErr %v", tt.block, err, tt.wantErr)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -14,6 +16,12 @@ import (
 // Run starts a static file server for local development.
 // Handles SIGINT/SIGTERM for graceful shutdown.
 func Run(port string, dir string) error {
+	return RunFS(port, os.DirFS(dir))
+}
+
+// RunFS is like Run but serves files from fsys, such as an
+// engine.MemoryOutput holding an in-memory build.
+func RunFS(port string, fsys fs.FS) error {
 	if port == "" {
 		port = "3000"
 	}
@@ -22,7 +30,7 @@ func Run(port string, dir string) error {
 	defer stop()
 
 	mux := http.NewServeMux()
-	mux.Handle("/", withLogging(http.FileServer(http.Dir(dir))))
+	mux.Handle("/", Handler(fsys))
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
@@ -44,7 +52,7 @@ func Run(port string, dir string) error {
 		}
 	}()
 
-	slog.Info("starting dev server", "url", fmt.Sprintf("http://localhost:%s", port), "dir", dir)
+	slog.Info("starting dev server", "url", fmt.Sprintf("http://localhost:%s", port))
 
 	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("server error: %w", err)
@@ -53,6 +61,11 @@ func Run(port string, dir string) error {
 	return nil
 }
 
+// Handler serves the static files in fsys with request logging.
+func Handler(fsys fs.FS) http.Handler {
+	return withLogging(http.FileServerFS(fsys))
+}
+
 // withLogging wraps an http.Handler to log each request.
 func withLogging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
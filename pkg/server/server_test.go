@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"maciejadamski/pkg/engine"
+	"maciejadamski/pkg/markdown"
+	"maciejadamski/pkg/website"
+
+	"github.com/a-h/templ"
+)
+
+func TestHandler_InMemoryBuild(t *testing.T) {
+	out := engine.NewMemoryOutput()
+	opts := engine.BuildOptions{
+		ConfigFS:  fstest.MapFS{"site.yaml": {Data: []byte("name: Test\nurl: https://example.com\n")}},
+		ContentFS: fstest.MapFS{},
+		StaticFS:  fstest.MapFS{"app.css": {Data: []byte("body {}")}},
+		Output:    out,
+	}
+	components := engine.ComponentRegistry{
+		Index: func(website.SiteConfig, website.SEO, []markdown.Post) templ.Component {
+			return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+				_, err := io.WriteString(w, "<h1>Home</h1>")
+				return err
+			})
+		},
+	}
+	if err := engine.Build(context.Background(), components, opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	srv := httptest.NewServer(Handler(out))
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/", code: http.StatusOK, body: "<h1>Home</h1>"},
+		{path: "/static/app.css", code: http.StatusOK, body: "body {}"},
+		{path: "/missing", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.code {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.code)
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SocialLink is a named link to an author's profile elsewhere.
+type SocialLink struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// Author is a post author loaded from config/authors.yaml.
+type Author struct {
+	// ID is the key in authors.yaml, used in frontmatter and URLs.
+	ID       string       `yaml:"-"`
+	Name     string       `yaml:"name"`
+	JobTitle string       `yaml:"job_title"`
+	Bio      string       `yaml:"bio"`
+	Avatar   string       `yaml:"avatar"`
+	Social   []SocialLink `yaml:"social"`
+	SameAs   []string     `yaml:"same_as"`
+}
+
+// LoadAuthors reads author profiles keyed by ID from a YAML file.
+// Returns an empty map if the file doesn't exist.
+func LoadAuthors(path string) (map[string]Author, error) {
+	return LoadAuthorsFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// LoadAuthorsFS reads author profiles from a YAML file in fsys.
+// A missing file yields no authors.
+func LoadAuthorsFS(fsys fs.FS, name string) (map[string]Author, error) {
+	authors := make(map[string]Author)
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return authors, nil
+		}
+		return nil, fmt.Errorf("reading authors file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &authors); err != nil {
+		return nil, fmt.Errorf("parsing authors file: %w", err)
+	}
+
+	for id, a := range authors {
+		if a.Name == "" {
+			return nil, fmt.Errorf("author %q: name is required", id)
+		}
+		a.ID = id
+		authors[id] = a
+	}
+
+	return authors, nil
+}
+
+// ResolveAuthor finds the author profile for a frontmatter author value.
+// Matches the author ID first, then the display name (case-insensitive).
+// Authors sharing a name resolve to the one with the lowest ID.
+func ResolveAuthor(site SiteConfig, value string) (Author, bool) {
+	if value == "" {
+		return Author{}, false
+	}
+	if a, ok := site.Authors[value]; ok {
+		return a, true
+	}
+	for _, a := range SortedAuthors(site) {
+		if strings.EqualFold(a.Name, value) {
+			return a, true
+		}
+	}
+	return Author{}, false
+}
+
+// SortedAuthors returns all author profiles ordered by ID.
+func SortedAuthors(site SiteConfig) []Author {
+	authors := make([]Author, 0, len(site.Authors))
+	for _, a := range site.Authors {
+		authors = append(authors, a)
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].ID < authors[j].ID })
+	return authors
+}
+
+// AuthorPath returns the root-relative URL of an author's profile page.
+func AuthorPath(author Author) string {
+	return "/authors/" + author.ID + "/"
+}
+
+// AuthorSameAs returns the author's sameAs URLs followed by any social links not already listed.
+func AuthorSameAs(author Author) []string {
+	sameAs := append([]string(nil), author.SameAs...)
+	for _, link := range author.Social {
+		if link.URL != "" && !slices.Contains(sameAs, link.URL) {
+			sameAs = append(sameAs, link.URL)
+		}
+	}
+	return sameAs
+}
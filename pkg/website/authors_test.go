@@ -0,0 +1,111 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAuthors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authors.yaml")
+
+	content := []byte(`
+jane:
+  name: Jane Doe
+  bio: Writes about Go.
+  social:
+    - name: GitHub
+      url: https://github.com/jane
+`)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	authors, err := LoadAuthors(path)
+	if err != nil {
+		t.Fatalf("LoadAuthors() error = %v", err)
+	}
+	jane, ok := authors["jane"]
+	if !ok {
+		t.Fatal("LoadAuthors() missing author jane")
+	}
+	if jane.ID != "jane" {
+		t.Errorf("jane.ID = %q, want jane", jane.ID)
+	}
+	if len(jane.Social) != 1 {
+		t.Errorf("len(jane.Social) = %d, want 1", len(jane.Social))
+	}
+}
+
+func TestLoadAuthors_Missing(t *testing.T) {
+	authors, err := LoadAuthors("/non/existent/authors.yaml")
+	if err != nil {
+		t.Fatalf("LoadAuthors() error = %v, want nil for missing file", err)
+	}
+	if len(authors) != 0 {
+		t.Errorf("len(authors) = %d, want 0", len(authors))
+	}
+}
+
+func TestLoadAuthors_MissingName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authors.yaml")
+	if err := os.WriteFile(path, []byte("jane:\n  bio: No name\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadAuthors(path); err == nil {
+		t.Error("LoadAuthors() expected error for author without name, got nil")
+	}
+}
+
+func TestResolveAuthor(t *testing.T) {
+	site := SiteConfig{
+		Authors: map[string]Author{
+			"jane": {ID: "jane", Name: "Jane Doe"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		value  string
+		wantOK bool
+	}{
+		{name: "by id", value: "jane", wantOK: true},
+		{name: "by display name", value: "jane doe", wantOK: true},
+		{name: "unknown author", value: "John Smith", wantOK: false},
+		{name: "empty value", value: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ResolveAuthor(site, tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("ResolveAuthor(%q) ok = %v, want %v", tt.value, ok, tt.wantOK)
+			}
+			if ok && got.ID != "jane" {
+				t.Errorf("ResolveAuthor(%q).ID = %q, want jane", tt.value, got.ID)
+			}
+		})
+	}
+}
+
+func TestResolveAuthor_SharedName(t *testing.T) {
+	site := SiteConfig{
+		Authors: map[string]Author{
+			"jane":   {ID: "jane", Name: "J. Doe"},
+			"john":   {ID: "john", Name: "J. Doe"},
+			"j. doe": {ID: "j. doe", Name: "Someone Else"},
+			"alice":  {ID: "alice", Name: "J. Doe"},
+		},
+	}
+	for range 20 {
+		if got, _ := ResolveAuthor(site, "J. Doe"); got.ID != "alice" {
+			t.Fatalf("ResolveAuthor(name) = %q, want the lowest ID alice", got.ID)
+		}
+	}
+	if got, _ := ResolveAuthor(site, "j. doe"); got.ID != "j. doe" {
+		t.Errorf("ResolveAuthor(id) = %q, want the ID match", got.ID)
+	}
+}
@@ -0,0 +1,39 @@
+package website
+
+import (
+	"context"
+	"time"
+)
+
+// contextKey is the type of build-scoped context keys set by the engine.
+type contextKey int
+
+const (
+	pagePathKey contextKey = iota
+	buildTimeKey
+)
+
+// WithPagePath returns ctx carrying the URL path of the page being rendered.
+func WithPagePath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, pagePathKey, path)
+}
+
+// PagePath returns the URL path of the page being rendered ("/blog/my-post/"),
+// or "" outside a build.
+func PagePath(ctx context.Context) string {
+	path, _ := ctx.Value(pagePathKey).(string)
+	return path
+}
+
+// WithBuildTime returns ctx carrying the time the build started.
+func WithBuildTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, buildTimeKey, t)
+}
+
+// BuildTime returns the time the build started, or the current time outside a build.
+func BuildTime(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(buildTimeKey).(time.Time); ok {
+		return t
+	}
+	return time.Now()
+}
@@ -0,0 +1,26 @@
+package website
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBuildContext(t *testing.T) {
+	ctx := context.Background()
+	if got := PagePath(ctx); got != "" {
+		t.Errorf("PagePath(empty) = %q, want empty", got)
+	}
+	if got := BuildTime(ctx); got.IsZero() {
+		t.Error("BuildTime(empty) is zero, want current time")
+	}
+
+	built := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	ctx = WithBuildTime(WithPagePath(ctx, "/blog/post/"), built)
+	if got := PagePath(ctx); got != "/blog/post/" {
+		t.Errorf("PagePath() = %q, want /blog/post/", got)
+	}
+	if got := BuildTime(ctx); !got.Equal(built) {
+		t.Errorf("BuildTime() = %v, want %v", got, built)
+	}
+}
@@ -0,0 +1,60 @@
+package website
+
+import (
+	"strings"
+	"time"
+)
+
+// monthNames maps a base language code to localized month names (January first).
+// Languages that inflect months use the genitive form, as dates always include a day.
+var monthNames = map[string][12]string{
+	"en": {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	"pl": {"stycznia", "lutego", "marca", "kwietnia", "maja", "czerwca", "lipca", "sierpnia", "września", "października", "listopada", "grudnia"},
+	"de": {"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	"fr": {"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	"es": {"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+}
+
+// shortMonthNames maps a base language code to abbreviated month names (January first).
+var shortMonthNames = map[string][12]string{
+	"en": {"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	"pl": {"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru"},
+	"de": {"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+	"fr": {"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+	"es": {"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+}
+
+// FormatDate formats t in the site time zone using the site date format,
+// with month names localized by the site language. Zero time returns "".
+// Unsupported languages fall back to English month names.
+func FormatDate(site SiteConfig, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	t = t.In(GetLocation(site))
+	layout := GetDateFormat(site)
+	out := t.Format(layout)
+
+	lang := baseLanguage(GetLanguage(site))
+	i := int(t.Month()) - 1
+	switch {
+	case strings.Contains(layout, "January"):
+		if names, ok := monthNames[lang]; ok {
+			out = strings.Replace(out, t.Month().String(), names[i], 1)
+		}
+	case strings.Contains(layout, "Jan"):
+		if names, ok := shortMonthNames[lang]; ok {
+			out = strings.Replace(out, t.Month().String()[:3], names[i], 1)
+		}
+	}
+	return out
+}
+
+// baseLanguage strips the region from a language tag ("pl-PL" -> "pl").
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		return lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,68 @@
+package website
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2026, 1, 15, 23, 30, 0, 0, time.UTC)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		site SiteConfig
+		date time.Time
+		want string
+	}{
+		{
+			name: "default format and language",
+			site: SiteConfig{},
+			date: date,
+			want: "January 15, 2026",
+		},
+		{
+			name: "site timezone shifts the day",
+			site: SiteConfig{Location: tokyo},
+			date: date,
+			want: "January 16, 2026",
+		},
+		{
+			name: "polish month names",
+			site: SiteConfig{Language: "pl", DateFormat: "2 January 2006"},
+			date: date,
+			want: "15 stycznia 2026",
+		},
+		{
+			name: "short german month names with region tag",
+			site: SiteConfig{Language: "de-DE", DateFormat: "02. Jan 2006"},
+			date: date,
+			want: "15. Jan. 2026",
+		},
+		{
+			name: "unsupported language falls back to English",
+			site: SiteConfig{Language: "xx"},
+			date: date,
+			want: "January 15, 2026",
+		},
+		{
+			name: "zero time returns empty",
+			site: SiteConfig{},
+			date: time.Time{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatDate(tt.site, tt.date)
+			if got != tt.want {
+				t.Errorf("FormatDate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,14 @@
 package website
 
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 // FontConfig holds Google Fonts configuration for a font family.
 type FontConfig struct {
 	URL    string
@@ -12,13 +21,153 @@ var fontRegistry = map[FontFamily]FontConfig{
 		URL:    "https://fonts.googleapis.com/css2?family=Google+Sans+Flex:opsz,wght@6..144,1..1000&family=Inter:ital,opsz,wght@0,14..32,100..900;1,14..32,100..900&display=swap",
 		Family: "'Google Sans Flex', 'Inter', sans-serif",
 	},
+	FontJetBrainsMono: {
+		URL:    "https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@100..800&display=swap",
+		Family: "'JetBrains Mono', monospace",
+	},
+}
+
+// DefaultFontFamilies are loaded when site.yaml sets no font_family.
+var DefaultFontFamilies = FontFamilies{FontGoogleSansFlex, FontJetBrainsMono}
+
+// FontFamilies lists the font families a site loads. In site.yaml it is a
+// single name or a list of names.
+type FontFamilies []FontFamily
+
+// UnmarshalYAML accepts a single family name as well as a list.
+func (f *FontFamilies) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*f = FontFamilies{FontFamily(node.Value)}
+		return nil
+	}
+	var names []FontFamily
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	*f = names
+	return nil
+}
+
+// FontsConfig declares self-hosted font families by the name used in
+// font_family. Their files are read from the fonts directory. Subset strips
+// the glyphs the generated pages don't use from TrueType files.
+type FontsConfig struct {
+	Families map[FontFamily]LocalFont `yaml:"families"`
+	Subset   bool                     `yaml:"subset"`
+}
+
+// LocalFont is a self-hosted font family. Family is the CSS font-family name
+// that theme.yaml refers to in font_sans or font_mono.
+type LocalFont struct {
+	Family string     `yaml:"family"`
+	Faces  []FontFace `yaml:"faces"`
+}
+
+// FontFace is one font file of a family. Weight is a CSS font-weight, or a
+// range such as "100 900" for variable fonts; empty means 400. Style is
+// normal, italic or oblique; empty means normal. Preload adds a preload hint
+// for the file to every page.
+type FontFace struct {
+	File    string `yaml:"file"`
+	Weight  string `yaml:"weight"`
+	Style   string `yaml:"style"`
+	Preload bool   `yaml:"preload"`
+}
+
+// FontAssets holds the URLs of the self-hosted fonts written by the build.
+type FontAssets struct {
+	// Stylesheet holds the @font-face rules; empty without local fonts.
+	Stylesheet string
+	Preload    []FontPreload
+}
+
+// FontPreload is a font file to preload, with its MIME type.
+type FontPreload struct {
+	URL  string
+	Type string
+}
+
+// fontFormats maps font file extensions to their CSS format and MIME type.
+var fontFormats = map[string]struct{ Format, Type string }{
+	".woff2": {"woff2", "font/woff2"},
+	".woff":  {"woff", "font/woff"},
+	".ttf":   {"truetype", "font/ttf"},
+	".otf":   {"opentype", "font/otf"},
+}
+
+// FontFormat returns the CSS format and MIME type of a font file, by extension.
+func FontFormat(file string) (format, mimeType string, ok bool) {
+	f, ok := fontFormats[strings.ToLower(path.Ext(file))]
+	return f.Format, f.Type, ok
+}
+
+// SiteFontFamilies returns the font families the site loads.
+func SiteFontFamilies(site SiteConfig) FontFamilies {
+	if len(site.FontFamily) == 0 {
+		return DefaultFontFamilies
+	}
+	return site.FontFamily
+}
+
+// GoogleFontURLs returns the stylesheets of the Google Fonts families the
+// site loads. Self-hosted families take precedence over Google fonts of the
+// same name.
+func GoogleFontURLs(site SiteConfig) []string {
+	var urls []string
+	for _, name := range SiteFontFamilies(site) {
+		if _, local := site.Fonts.Families[name]; local {
+			continue
+		}
+		if cfg, ok := fontRegistry[name]; ok {
+			urls = append(urls, cfg.URL)
+		}
+	}
+	return urls
+}
+
+// LocalFonts returns the self-hosted families the site loads, in
+// font_family order.
+func LocalFonts(site SiteConfig) []LocalFont {
+	var fonts []LocalFont
+	for _, name := range SiteFontFamilies(site) {
+		if font, ok := site.Fonts.Families[name]; ok {
+			fonts = append(fonts, font)
+		}
+	}
+	return fonts
+}
+
+// validateFonts checks that every font_family is a Google font or a
+// self-hosted family, and that self-hosted faces name supported files.
+func validateFonts(site SiteConfig) error {
+	for _, name := range SiteFontFamilies(site) {
+		_, local := site.Fonts.Families[name]
+		if _, google := fontRegistry[name]; !local && !google {
+			return fmt.Errorf("unknown font_family %q: add it under fonts.families or use one of %s", name, knownFonts())
+		}
+	}
+	for name, font := range site.Fonts.Families {
+		if font.Family == "" {
+			return fmt.Errorf("fonts.families.%s: missing family", name)
+		}
+		if len(font.Faces) == 0 {
+			return fmt.Errorf("fonts.families.%s: no faces", name)
+		}
+		for _, face := range font.Faces {
+			if _, _, ok := FontFormat(face.File); !ok {
+				return fmt.Errorf("fonts.families.%s: %q is not a .woff2, .woff, .ttf or .otf file", name, face.File)
+			}
+		}
+	}
+	return nil
 }
 
-// GetFontConfig returns the font configuration for a given FontFamily.
-// Returns GoogleSansFlex configuration as fallback for unknown fonts.
-func GetFontConfig(font FontFamily) FontConfig {
-	if cfg, ok := fontRegistry[font]; ok {
-		return cfg
+// knownFonts lists the Google font names for error messages.
+func knownFonts() string {
+	names := make([]string, 0, len(fontRegistry))
+	for name := range fontRegistry {
+		names = append(names, string(name))
 	}
-	return fontRegistry[FontGoogleSansFlex]
+	slices.Sort(names)
+	return strings.Join(names, ", ")
 }
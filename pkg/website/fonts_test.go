@@ -1,31 +1,69 @@
 package website
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
 
-func TestGetFontConfig(t *testing.T) {
+func TestLoadSiteConfig_Fonts(t *testing.T) {
+	local := `
+fonts:
+  families:
+    inter:
+      family: Inter
+      faces:
+        - file: Inter.woff2
+          weight: "100 900"
+          preload: true
+`
 	tests := []struct {
-		name string
-		font FontFamily
-		want string // Family name
+		name       string
+		config     string
+		wantGoogle int
+		wantLocal  []string
+		wantErr    string
 	}{
-		{
-			name: "existing font",
-			font: FontGoogleSansFlex,
-			want: "'Google Sans Flex', 'Inter', sans-serif",
-		},
-		{
-			name: "unknown font fallback",
-			font: "Comic Sans",
-			want: "'Google Sans Flex', 'Inter', sans-serif",
-		},
+		{name: "default", wantGoogle: 2},
+		{name: "single name", config: "font_family: jetbrains-mono\n", wantGoogle: 1},
+		{name: "local and google", config: "font_family: [inter, jetbrains-mono]\n" + local, wantGoogle: 1, wantLocal: []string{"Inter"}},
+		{name: "local overrides google", config: "font_family: [jetbrains-mono]\n" + strings.Replace(local, "inter:", "jetbrains-mono:", 1), wantLocal: []string{"Inter"}},
+		{name: "unknown", config: "font_family: Comic Sans\n", wantErr: "unknown font_family \"Comic Sans\""},
+		{name: "unsupported file", config: "font_family: inter\n" + strings.Replace(local, "Inter.woff2", "Inter.eot", 1), wantErr: "is not a .woff2"},
+		{name: "no faces", config: "font_family: inter\nfonts:\n  families:\n    inter:\n      family: Inter\n", wantErr: "no faces"},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := GetFontConfig(tt.font)
-			if got.Family != tt.want {
-				t.Errorf("GetFontConfig().Family = %v, want %v", got.Family, tt.want)
+			fsys := fstest.MapFS{"site.yaml": {Data: []byte("name: Site\n" + tt.config)}}
+			cfg, err := LoadSiteConfigFS(fsys, "site.yaml")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadSiteConfigFS() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSiteConfigFS() error = %v", err)
+			}
+			if got := GoogleFontURLs(cfg); len(got) != tt.wantGoogle {
+				t.Errorf("GoogleFontURLs() = %v, want %d URLs", got, tt.wantGoogle)
+			}
+			var families []string
+			for _, font := range LocalFonts(cfg) {
+				families = append(families, font.Family)
+			}
+			if !slices.Equal(families, tt.wantLocal) {
+				t.Errorf("LocalFonts() = %v, want %v", families, tt.wantLocal)
 			}
 		})
 	}
 }
+
+func TestFontFormat(t *testing.T) {
+	for file, want := range map[string]string{"a.woff2": "font/woff2", "b/C.TTF": "font/ttf", "d.otf": "font/otf", "e.svg": ""} {
+		if _, got, _ := FontFormat(file); got != want {
+			t.Errorf("FontFormat(%q) type = %q, want %q", file, got, want)
+		}
+	}
+}
@@ -5,6 +5,11 @@ func TailwindCDN() string {
 	return "https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4"
 }
 
+// TailwindElementsCDN returns the Tailwind Plus Elements v1 CDN URL.
+func TailwindElementsCDN() string {
+	return "https://cdn.jsdelivr.net/npm/@tailwindplus/elements@1"
+}
+
 // AlpineJSCDN returns the Alpine.js CDN URL (v3.15.4).
 func AlpineJSCDN() string {
 	return "https://cdn.jsdelivr.net/npm/alpinejs@3.15.4/dist/cdn.min.js"
@@ -1,6 +1,10 @@
 package website
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // AbsoluteURL joins the site URL with a path, ensuring a valid absolute URL.
 func AbsoluteURL(site SiteConfig, path string) string {
@@ -15,6 +19,25 @@ func AbsoluteURL(site SiteConfig, path string) string {
 	return base + "/" + cleanPath
 }
 
+// OutputURLPath returns the URL path an output file is served at
+// ("blog/post/index.html" -> "/blog/post/", "404.html" -> "/404.html").
+func OutputURLPath(name string) string {
+	name = "/" + filepath.ToSlash(name)
+	if strings.HasSuffix(name, "/index.html") {
+		return strings.TrimSuffix(name, "index.html")
+	}
+	return name
+}
+
+// Asset returns the fingerprinted URL path of a static file, or path itself
+// when the file is not fingerprinted.
+func Asset(site SiteConfig, path string) string {
+	if fp, ok := site.AssetURLs[path]; ok {
+		return fp
+	}
+	return path
+}
+
 // GetLanguage returns the site language or defaults to "en".
 func GetLanguage(site SiteConfig) string {
 	if site.Language != "" {
@@ -31,6 +54,31 @@ func GetLocale(site SiteConfig) string {
 	return "en_US"
 }
 
+// EnvProduction is the environment of builds that are deployed.
+const EnvProduction = "production"
+
+// IsProduction reports whether the site is built for production. An unset
+// environment counts as production.
+func IsProduction(site SiteConfig) bool {
+	return site.Environment == "" || site.Environment == EnvProduction
+}
+
+// GetLocation returns the site time zone or defaults to UTC.
+func GetLocation(site SiteConfig) *time.Location {
+	if site.Location != nil {
+		return site.Location
+	}
+	return time.UTC
+}
+
+// GetDateFormat returns the Go layout used to display dates or defaults to "January 02, 2006".
+func GetDateFormat(site SiteConfig) string {
+	if site.DateFormat != "" {
+		return site.DateFormat
+	}
+	return "January 02, 2006"
+}
+
 // GetRobots returns the robots meta tag content.
 func GetRobots(seo SEO) string {
 	if seo.NoIndex {
@@ -39,10 +87,10 @@ func GetRobots(seo SEO) string {
 	return "index, follow"
 }
 
-// GetCanonical returns the canonical URL for the page.
+// GetCanonical returns the absolute canonical URL for the page.
 func GetCanonical(site SiteConfig, seo SEO, currentPath string) string {
 	if seo.Canonical != "" {
-		return seo.Canonical
+		return AbsoluteURL(site, seo.Canonical)
 	}
 	return AbsoluteURL(site, currentPath)
 }
@@ -66,25 +114,28 @@ func GetOGImage(site SiteConfig, seo SEO) string {
 	return ""
 }
 
-// GetOGImageWidth returns the OG image width.
+// GetOGImageWidth returns the OG image width. The site default only
+// applies to the default image.
 func GetOGImageWidth(site SiteConfig, seo SEO) string {
-	if seo.OGImageWidth != "" {
+	if seo.OGImageWidth != "" || seo.OGImage != "" {
 		return seo.OGImageWidth
 	}
 	return site.DefaultImageWidth
 }
 
-// GetOGImageHeight returns the OG image height.
+// GetOGImageHeight returns the OG image height. The site default only
+// applies to the default image.
 func GetOGImageHeight(site SiteConfig, seo SEO) string {
-	if seo.OGImageHeight != "" {
+	if seo.OGImageHeight != "" || seo.OGImage != "" {
 		return seo.OGImageHeight
 	}
 	return site.DefaultImageHeight
 }
 
-// GetOGImageAlt returns the OG image alt text.
+// GetOGImageAlt returns the OG image alt text. The site default only
+// applies to the default image.
 func GetOGImageAlt(site SiteConfig, seo SEO) string {
-	if seo.OGImageAlt != "" {
+	if seo.OGImageAlt != "" || seo.OGImage != "" {
 		return seo.OGImageAlt
 	}
 	return site.DefaultImageAlt
@@ -67,6 +67,23 @@ func TestAbsoluteURL(t *testing.T) {
 	})
 }
 
+func TestOutputURLPath(t *testing.T) {
+	tests := map[string]string{
+		"index.html":              "/",
+		"blog/index.html":         "/blog/",
+		"blog/post/index.html":    "/blog/post/",
+		"pl/blog/index.html":      "/pl/blog/",
+		"authors/jane/index.html": "/authors/jane/",
+		"404.html":                "/404.html",
+		"about.html":              "/about.html",
+	}
+	for name, want := range tests {
+		if got := OutputURLPath(name); got != want {
+			t.Errorf("OutputURLPath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
 func TestGetLanguage(t *testing.T) {
 	tests := []struct {
 		name string
@@ -162,6 +179,12 @@ func TestGetCanonical(t *testing.T) {
 			path: "/page",
 			want: "https://other.com/page",
 		},
+		{
+			name: "root-relative canonical",
+			seo:  SEO{Canonical: "/original/"},
+			path: "/page",
+			want: "https://example.com/original/",
+		},
 		{
 			name: "generated canonical",
 			seo:  SEO{},
@@ -253,3 +276,52 @@ func TestGetOGImage(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOGImageDetails(t *testing.T) {
+	site := SiteConfig{DefaultImage: "/default.jpg", DefaultImageWidth: "1200", DefaultImageHeight: "630", DefaultImageAlt: "Default"}
+	tests := []struct {
+		name string
+		seo  SEO
+		want string // width x height alt
+	}{
+		{name: "default image", seo: SEO{}, want: "1200x630 Default"},
+		{name: "custom image", seo: SEO{OGImage: "/custom.jpg"}, want: "x "},
+		{name: "custom image with details", seo: SEO{OGImage: "/custom.jpg", OGImageWidth: "800", OGImageHeight: "600", OGImageAlt: "Custom"}, want: "800x600 Custom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetOGImageWidth(site, tt.seo) + "x" + GetOGImageHeight(site, tt.seo) + " " + GetOGImageAlt(site, tt.seo)
+			if got != tt.want {
+				t.Errorf("OG image details = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsProduction(t *testing.T) {
+	tests := []struct {
+		env  string
+		want bool
+	}{
+		{"", true},
+		{"production", true},
+		{"development", false},
+		{"staging", false},
+	}
+	for _, tt := range tests {
+		if got := IsProduction(SiteConfig{Environment: tt.env}); got != tt.want {
+			t.Errorf("IsProduction(%q) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestAsset(t *testing.T) {
+	site := SiteConfig{AssetURLs: map[string]string{"/static/css/prose.css": "/static/css/prose.1234abcd.css"}}
+
+	if got := Asset(site, "/static/css/prose.css"); got != "/static/css/prose.1234abcd.css" {
+		t.Errorf("Asset() = %q, want fingerprinted path", got)
+	}
+	if got := Asset(site, "/static/og-image.png"); got != "/static/og-image.png" {
+		t.Errorf("Asset() = %q, want path unchanged", got)
+	}
+}
@@ -0,0 +1,135 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LanguageConfig describes a content language published under its own URL prefix.
+type LanguageConfig struct {
+	Code       string `yaml:"code"`
+	Locale     string `yaml:"locale"`
+	Name       string `yaml:"name"`
+	DateFormat string `yaml:"date_format"`
+}
+
+// Alternate is a translated version of a page, used for hreflang links.
+type Alternate struct {
+	Language string
+	Path     string
+}
+
+// Bundle maps UI string keys to translated text for one language.
+type Bundle map[string]string
+
+// defaultBundle holds the built-in English UI strings.
+// Bundles loaded from config override or extend these keys.
+var defaultBundle = Bundle{
+	"blog.title":       "Blog",
+	"blog.subtitle":    "Thoughts, ideas, and tutorials",
+	"blog.empty":       "No posts yet. Check back soon!",
+	"blog.back":        "Back to blog",
+	"post.by":          "by",
+	"nav.open_menu":    "Open menu",
+	"nav.close_menu":   "Close menu",
+	"footer.copyright": "All rights reserved.",
+}
+
+// Languages returns all site languages, the default language first.
+func Languages(site SiteConfig) []LanguageConfig {
+	langs := []LanguageConfig{{Code: GetLanguage(site), Locale: GetLocale(site)}}
+	for _, l := range site.Languages {
+		if l.Code != "" && l.Code != langs[0].Code {
+			langs = append(langs, l)
+		}
+	}
+	return langs
+}
+
+// ForLanguage returns a copy of the site config localized to lang.
+// The original language becomes DefaultLanguage, which controls URL prefixes.
+func ForLanguage(site SiteConfig, lang LanguageConfig) SiteConfig {
+	if site.DefaultLanguage == "" {
+		site.DefaultLanguage = GetLanguage(site)
+	}
+	site.Language = lang.Code
+	if lang.Locale != "" {
+		site.Locale = lang.Locale
+	}
+	if lang.DateFormat != "" {
+		site.DateFormat = lang.DateFormat
+	}
+	return site
+}
+
+// LanguagePrefix returns the URL prefix for the site language ("" or "/pl").
+func LanguagePrefix(site SiteConfig) string {
+	lang := GetLanguage(site)
+	if site.DefaultLanguage == "" || lang == site.DefaultLanguage {
+		return ""
+	}
+	return "/" + lang
+}
+
+// LocalizedPath prefixes a root-relative path with the site language prefix.
+func LocalizedPath(site SiteConfig, path string) string {
+	return LanguagePrefix(site) + path
+}
+
+// T returns the UI string for key in the site language.
+// Falls back to the default language, then the built-in English strings, then the key itself.
+func T(site SiteConfig, key string) string {
+	for _, lang := range []string{GetLanguage(site), site.DefaultLanguage} {
+		if v, ok := site.Translations[baseLanguage(lang)][key]; ok {
+			return v
+		}
+	}
+	if v, ok := defaultBundle[key]; ok {
+		return v
+	}
+	return key
+}
+
+// LoadTranslations reads UI string bundles from <dir>/<lang>.yaml files.
+// Returns an empty map if the directory doesn't exist.
+func LoadTranslations(dir string) (map[string]Bundle, error) {
+	return LoadTranslationsFS(os.DirFS(filepath.Dir(dir)), filepath.Base(dir))
+}
+
+// LoadTranslationsFS reads <lang>.yaml bundles from dir in fsys.
+// A missing directory yields no bundles.
+func LoadTranslationsFS(fsys fs.FS, dir string) (map[string]Bundle, error) {
+	bundles := make(map[string]Bundle)
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return bundles, nil
+		}
+		return nil, fmt.Errorf("reading translations directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading translations %s: %w", entry.Name(), err)
+		}
+		var bundle Bundle
+		if err := yaml.Unmarshal(data, &bundle); err != nil {
+			return nil, fmt.Errorf("parsing translations %s: %w", entry.Name(), err)
+		}
+		bundles[baseLanguage(strings.TrimSuffix(entry.Name(), ".yaml"))] = bundle
+	}
+
+	return bundles, nil
+}
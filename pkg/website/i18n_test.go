@@ -0,0 +1,112 @@
+package website
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLanguages(t *testing.T) {
+	site := SiteConfig{
+		Language: "en",
+		Languages: []LanguageConfig{
+			{Code: "pl", Locale: "pl_PL"},
+			{Code: "en"},
+		},
+	}
+
+	got := Languages(site)
+	if len(got) != 2 {
+		t.Fatalf("len(Languages()) = %d, want 2", len(got))
+	}
+	if got[0].Code != "en" || got[1].Code != "pl" {
+		t.Errorf("Languages() = %+v, want en then pl", got)
+	}
+}
+
+func TestLocalizedPath(t *testing.T) {
+	site := SiteConfig{Language: "en"}
+
+	tests := []struct {
+		name string
+		site SiteConfig
+		want string
+	}{
+		{
+			name: "site without languages",
+			site: site,
+			want: "/blog/",
+		},
+		{
+			name: "default language",
+			site: ForLanguage(site, LanguageConfig{Code: "en"}),
+			want: "/blog/",
+		},
+		{
+			name: "translated language",
+			site: ForLanguage(site, LanguageConfig{Code: "pl"}),
+			want: "/pl/blog/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LocalizedPath(tt.site, "/blog/")
+			if got != tt.want {
+				t.Errorf("LocalizedPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	site := SiteConfig{
+		Language: "en",
+		Translations: map[string]Bundle{
+			"en": {"custom": "Custom"},
+			"pl": {"blog.back": "Powrót do bloga"},
+		},
+	}
+	pl := ForLanguage(site, LanguageConfig{Code: "pl-PL"})
+
+	tests := []struct {
+		name string
+		site SiteConfig
+		key  string
+		want string
+	}{
+		{name: "translated string", site: pl, key: "blog.back", want: "Powrót do bloga"},
+		{name: "falls back to default language", site: pl, key: "custom", want: "Custom"},
+		{name: "falls back to built-in English", site: pl, key: "blog.empty", want: "No posts yet. Check back soon!"},
+		{name: "unknown key returns key", site: site, key: "missing.key", want: "missing.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := T(tt.site, tt.key)
+			if got != tt.want {
+				t.Errorf("T(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTranslations(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pl.yaml"), []byte(`blog.title: "Blog PL"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundles, err := LoadTranslations(dir)
+	if err != nil {
+		t.Fatalf("LoadTranslations() error = %v", err)
+	}
+	if bundles["pl"]["blog.title"] != "Blog PL" {
+		t.Errorf("bundles[pl][blog.title] = %q, want Blog PL", bundles["pl"]["blog.title"])
+	}
+
+	bundles, err = LoadTranslations(filepath.Join(dir, "missing"))
+	if err != nil || len(bundles) != 0 {
+		t.Errorf("LoadTranslations(missing) = %v, %v, want empty map and nil", bundles, err)
+	}
+}
@@ -0,0 +1,68 @@
+package website
+
+// Integration is a third-party service loaded by the templates, with the
+// Content-Security-Policy sources it needs keyed by directive. Scripts from
+// shared CDNs are allowed by their exact URL, not the whole CDN host.
+type Integration struct {
+	Name    string
+	Sources map[string][]string
+}
+
+// Integrations returns the integrations the site loads. Tailwind and
+// Tailwind Plus Elements are part of the base layout; Google Fonts is
+// included when a font_family is not self-hosted, and the others only when
+// enabled in the config.
+func Integrations(site SiteConfig) []Integration {
+	integrations := []Integration{
+		{
+			// The browser build injects the generated stylesheet at runtime.
+			Name: "tailwind",
+			Sources: map[string][]string{
+				"script-src": {TailwindCDN()},
+				"style-src":  {"'unsafe-inline'"},
+			},
+		},
+		{
+			Name: "tailwind-elements",
+			// The module loads its chunks from below the package URL.
+			Sources: map[string][]string{"script-src": {TailwindElementsCDN(), TailwindElementsCDN() + "/"}},
+		},
+	}
+	if len(GoogleFontURLs(site)) > 0 {
+		integrations = append(integrations, Integration{
+			Name: "google-fonts",
+			Sources: map[string][]string{
+				"style-src": {"https://fonts.googleapis.com"},
+				"font-src":  {"https://fonts.gstatic.com"},
+			},
+		})
+	}
+	if site.GoogleAnalyticsID != "" {
+		integrations = append(integrations, Integration{
+			Name: "google-analytics",
+			Sources: map[string][]string{
+				"script-src":  {"https://www.googletagmanager.com"},
+				"img-src":     {"https://www.googletagmanager.com", "https://www.google-analytics.com"},
+				"connect-src": {"https://www.google-analytics.com", "https://*.google-analytics.com", "https://*.analytics.google.com"},
+			},
+		})
+	}
+	if site.EnableAlpineJS {
+		// The standard Alpine build evaluates expressions with new Function.
+		integrations = append(integrations, Integration{
+			Name:    "alpine",
+			Sources: map[string][]string{"script-src": {AlpineJSCDN(), "'unsafe-eval'"}},
+		})
+	}
+	if site.EnableHTMX {
+		// HTMX injects its indicator styles.
+		integrations = append(integrations, Integration{
+			Name: "htmx",
+			Sources: map[string][]string{
+				"script-src": {HTMXCDN()},
+				"style-src":  {"'unsafe-inline'"},
+			},
+		})
+	}
+	return integrations
+}
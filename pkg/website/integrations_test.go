@@ -0,0 +1,54 @@
+package website
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntegrations(t *testing.T) {
+	names := func(site SiteConfig) map[string]bool {
+		got := map[string]bool{}
+		for _, i := range Integrations(site) {
+			got[i.Name] = true
+		}
+		return got
+	}
+
+	base := names(SiteConfig{})
+	for _, name := range []string{"tailwind", "tailwind-elements", "google-fonts"} {
+		if !base[name] {
+			t.Errorf("base integrations missing %s", name)
+		}
+	}
+	for _, name := range []string{"google-analytics", "alpine", "htmx"} {
+		if base[name] {
+			t.Errorf("disabled integration %s included", name)
+		}
+	}
+
+	local := names(SiteConfig{
+		FontFamily: FontFamilies{"inter"},
+		Fonts:      FontsConfig{Families: map[FontFamily]LocalFont{"inter": {Family: "Inter"}}},
+	})
+	if local["google-fonts"] {
+		t.Error("google-fonts included for self-hosted fonts only")
+	}
+
+	all := names(SiteConfig{GoogleAnalyticsID: "G-TEST", EnableAlpineJS: true, EnableHTMX: true})
+	for _, name := range []string{"google-analytics", "alpine", "htmx"} {
+		if !all[name] {
+			t.Errorf("enabled integration %s missing", name)
+		}
+	}
+}
+
+func TestIntegrations_ScriptSourcesArePaths(t *testing.T) {
+	site := SiteConfig{GoogleAnalyticsID: "G-TEST", EnableAlpineJS: true, EnableHTMX: true}
+	for _, i := range Integrations(site) {
+		for _, src := range i.Sources["script-src"] {
+			if rest, ok := strings.CutPrefix(src, "https://cdn.jsdelivr.net"); ok && !strings.HasPrefix(rest, "/npm/") {
+				t.Errorf("%s allows more than one package from cdn.jsdelivr.net: %q", i.Name, src)
+			}
+		}
+	}
+}
@@ -75,6 +75,39 @@ func BreadcrumbSchema(site SiteConfig, breadcrumbs []Breadcrumb) string {
 	return marshalSchema(schema)
 }
 
+// ProfilePageSchema generates JSON-LD for an author's ProfilePage.
+func ProfilePageSchema(site SiteConfig, author Author, currentPath string) string {
+	schema := map[string]any{
+		"@context":   "https://schema.org",
+		"@type":      "ProfilePage",
+		"url":        AbsoluteURL(site, currentPath),
+		"mainEntity": personSchema(site, author),
+	}
+	return marshalSchema(schema)
+}
+
+// personSchema builds a Person object from an author profile.
+func personSchema(site SiteConfig, author Author) map[string]any {
+	person := map[string]any{
+		"@type": "Person",
+		"name":  author.Name,
+		"url":   AbsoluteURL(site, AuthorPath(author)),
+	}
+	if author.JobTitle != "" {
+		person["jobTitle"] = author.JobTitle
+	}
+	if author.Bio != "" {
+		person["description"] = author.Bio
+	}
+	if author.Avatar != "" {
+		person["image"] = AbsoluteURL(site, author.Avatar)
+	}
+	if sameAs := AuthorSameAs(author); len(sameAs) > 0 {
+		person["sameAs"] = sameAs
+	}
+	return person
+}
+
 // articleSchema is the shared implementation for Article and BlogPosting.
 func articleSchema(site SiteConfig, seo SEO, currentPath string, schemaType string) string {
 	article := map[string]any{
@@ -113,8 +146,14 @@ func articleSchema(site SiteConfig, seo SEO, currentPath string, schemaType stri
 			"@type": "Person",
 			"name":  seo.ArticleAuthor,
 		}
+		if profile, ok := ResolveAuthor(site, seo.ArticleAuthor); ok {
+			author = personSchema(site, profile)
+		}
+		// The profile URL is only set when the author's page exists.
 		if seo.ArticleAuthorURL != "" {
 			author["url"] = seo.ArticleAuthorURL
+		} else {
+			delete(author, "url")
 		}
 		article["author"] = author
 	}
@@ -119,3 +119,46 @@ func TestBlogPostingSchema(t *testing.T) {
 		t.Errorf("BlogPostingSchema() type = %v, want BlogPosting", data["@type"])
 	}
 }
+
+func TestArticleSchema_AuthorProfile(t *testing.T) {
+	site := SiteConfig{
+		Name: "Test Site",
+		URL:  "https://example.com",
+		Authors: map[string]Author{
+			"jane": {
+				ID:     "jane",
+				Name:   "Jane Doe",
+				Bio:    "Writes about Go.",
+				SameAs: []string{"https://example.org/jane"},
+				Social: []SocialLink{{Name: "GitHub", URL: "https://github.com/jane"}},
+			},
+		},
+	}
+	seo := SEO{Title: "Test Post", ArticleAuthor: "Jane Doe", ArticleAuthorURL: "https://example.com/authors/jane/"}
+
+	got := BlogPostingSchema(site, seo, "/post")
+
+	var data map[string]any
+	if err := json.Unmarshal([]byte(got), &data); err != nil {
+		t.Fatalf("BlogPostingSchema() produced invalid JSON: %v", err)
+	}
+	author, ok := data["author"].(map[string]any)
+	if !ok {
+		t.Fatalf("BlogPostingSchema() author = %v, want object", data["author"])
+	}
+	if author["url"] != "https://example.com/authors/jane/" {
+		t.Errorf("author url = %v, want https://example.com/authors/jane/", author["url"])
+	}
+	if author["description"] != "Writes about Go." {
+		t.Errorf("author description = %v, want bio", author["description"])
+	}
+	sameAs, _ := author["sameAs"].([]any)
+	if len(sameAs) != 2 {
+		t.Errorf("author sameAs = %v, want sameAs plus social link", author["sameAs"])
+	}
+
+	seo.ArticleAuthorURL = ""
+	if got := BlogPostingSchema(site, seo, "/post"); strings.Contains(string(got), "/authors/jane/") {
+		t.Errorf("BlogPostingSchema() = %s, want no author url without an author page", got)
+	}
+}
@@ -1,8 +1,11 @@
 package website
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 )
@@ -68,11 +71,17 @@ func DefaultTheme() ThemeConfig {
 // LoadTheme loads theme configuration from a YAML file.
 // Returns default theme if file doesn't exist.
 func LoadTheme(path string) (ThemeConfig, error) {
+	return LoadThemeFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// LoadThemeFS reads theme configuration from a YAML file in fsys.
+// Returns the default theme if the file doesn't exist.
+func LoadThemeFS(fsys fs.FS, name string) (ThemeConfig, error) {
 	theme := DefaultTheme()
 
-	data, err := os.ReadFile(path)
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return theme, nil
 		}
 		return theme, fmt.Errorf("reading theme file: %w", err)
@@ -2,16 +2,21 @@ package website
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-// FontFamily represents supported Google Fonts.
+// FontFamily names a supported Google font or a self-hosted family
+// declared under fonts.families.
 type FontFamily string
 
 const (
 	FontGoogleSansFlex FontFamily = "google-sans-flex"
+	FontJetBrainsMono  FontFamily = "jetbrains-mono"
 )
 
 // Breadcrumb represents a single breadcrumb navigation item.
@@ -29,6 +34,13 @@ type SiteConfig struct {
 	Language    string `yaml:"language"`
 	Locale      string `yaml:"locale"`
 
+	// Languages published in addition to Language, each under its own URL prefix
+	Languages []LanguageConfig `yaml:"languages"`
+
+	// Dates
+	Timezone   string `yaml:"timezone"`
+	DateFormat string `yaml:"date_format"`
+
 	// Default images for SEO
 	DefaultImage       string `yaml:"default_image"`
 	DefaultImageAlt    string `yaml:"default_image_alt"`
@@ -47,13 +59,148 @@ type SiteConfig struct {
 	GoogleAnalyticsID         string `yaml:"google_analytics_id"`
 
 	// Frontend features
-	EnableAlpineJS bool       `yaml:"enable_alpine_js"`
-	EnableHTMX     bool       `yaml:"enable_htmx"`
-	FontFamily     FontFamily `yaml:"font_family"`
-	CustomCSS      []string   `yaml:"custom_css"`
+	EnableAlpineJS bool         `yaml:"enable_alpine_js"`
+	EnableHTMX     bool         `yaml:"enable_htmx"`
+	FontFamily     FontFamilies `yaml:"font_family"`
+	CustomCSS      []string     `yaml:"custom_css"`
+
+	// Self-hosted font families
+	Fonts FontsConfig `yaml:"fonts"`
+
+	// Raw HTML in markdown posts
+	RawHTML RawHTMLConfig `yaml:"raw_html"`
+
+	// Crawler rules for robots.txt
+	Robots RobotsConfig `yaml:"robots"`
+
+	// Minification of HTML and CSS output
+	Minify MinifyConfig `yaml:"minify"`
+
+	// Fingerprinting of static files
+	Assets AssetsConfig `yaml:"assets"`
+
+	// Response headers written to _headers
+	Headers HeadersConfig `yaml:"headers"`
+
+	// Generated Open Graph images for posts
+	OGImages OGImagesConfig `yaml:"og_images"`
+
+	// Post-build link checking
+	Links LinksConfig `yaml:"links"`
+
+	// Post-build accessibility audit
+	Accessibility AccessibilityConfig `yaml:"accessibility"`
+
+	// AssetURLs maps static file URL paths to their fingerprinted paths
+	// (set by the build, not from site.yaml).
+	AssetURLs map[string]string `yaml:"-"`
+
+	// FontAssets holds the URLs of the self-hosted fonts (set by the build,
+	// not from site.yaml).
+	FontAssets FontAssets `yaml:"-"`
+
+	// Environment names the build target, e.g. "production" or "development"
+	// (set by the build, not from site.yaml).
+	Environment string `yaml:"-"`
 
 	// Theme (loaded separately, not from site.yaml)
 	Theme ThemeConfig `yaml:"-"`
+
+	// Location is resolved from Timezone when the config is loaded.
+	Location *time.Location `yaml:"-"`
+
+	// DefaultLanguage is the unprefixed language, set by ForLanguage.
+	DefaultLanguage string `yaml:"-"`
+
+	// Authors holds author profiles by ID (loaded separately, not from site.yaml)
+	Authors map[string]Author `yaml:"-"`
+
+	// Translations holds UI string bundles by language (loaded separately, not from site.yaml)
+	Translations map[string]Bundle `yaml:"-"`
+}
+
+// RawHTMLConfig controls raw HTML in markdown posts.
+// Allow adds tags and attributes to the default sanitizer allowlist.
+type RawHTMLConfig struct {
+	Enabled bool                `yaml:"enabled"`
+	Strict  bool                `yaml:"strict"`
+	Allow   map[string][]string `yaml:"allow"`
+}
+
+// RobotsConfig controls robots.txt. Rules are written in order; without any,
+// every crawler is allowed. Sitemaps are listed after the site's own sitemap.
+// Outside production every crawler is disallowed unless IndexNonProduction is set.
+type RobotsConfig struct {
+	Rules              []RobotsRule `yaml:"rules"`
+	Sitemaps           []string     `yaml:"sitemaps"`
+	IndexNonProduction bool         `yaml:"index_non_production"`
+}
+
+// RobotsRule is one robots.txt group for a set of user agents.
+type RobotsRule struct {
+	UserAgents []string `yaml:"user_agents"`
+	Allow      []string `yaml:"allow"`
+	Disallow   []string `yaml:"disallow"`
+	CrawlDelay int      `yaml:"crawl_delay"`
+}
+
+// MinifyConfig controls minification of rendered HTML and static CSS.
+// Environments lists the environments it applies to; empty means production only.
+type MinifyConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Environments []string `yaml:"environments"`
+}
+
+// AssetsConfig controls content-hash fingerprinting of static files.
+// Extensions lists the fingerprinted file types; empty means .css and .js.
+type AssetsConfig struct {
+	Fingerprint bool     `yaml:"fingerprint"`
+	Extensions  []string `yaml:"extensions"`
+}
+
+// HeadersConfig controls the generated _headers file. Headers add to or
+// override the default security headers sent with every response, CSP adds
+// sources to Content-Security-Policy directives, and Cache replaces the
+// default Cache-Control rules.
+type HeadersConfig struct {
+	Enabled bool                `yaml:"enabled"`
+	Headers map[string]string   `yaml:"headers"`
+	CSP     map[string][]string `yaml:"csp"`
+	Cache   []CacheRule         `yaml:"cache"`
+}
+
+// OGImagesConfig controls the Open Graph images rendered for posts without
+// an image in their frontmatter. TitleFont and TextFont name TTF or OTF
+// files in the fonts directory; empty means the built-in Go fonts.
+type OGImagesConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	TitleFont string `yaml:"title_font"`
+	TextFont  string `yaml:"text_font"`
+}
+
+// LinksConfig controls the link checker that runs on the finished output.
+// External adds a request to every off-site URL. Ignore lists URL prefixes
+// that are not checked, e.g. sites that block automated requests.
+type LinksConfig struct {
+	Check    bool     `yaml:"check"`
+	External bool     `yaml:"external"`
+	Ignore   []string `yaml:"ignore"`
+}
+
+// AccessibilityConfig controls the audit that runs on the finished output.
+// FailOn is the severity ("error" or "warning") at which issues fail the
+// build; "off" only reports them, and empty means "error". Rules overrides
+// the severity of individual rules, or disables them with "off".
+type AccessibilityConfig struct {
+	Audit  bool              `yaml:"audit"`
+	FailOn string            `yaml:"fail_on"`
+	Rules  map[string]string `yaml:"rules"`
+}
+
+// CacheRule sets Cache-Control for the paths matching Path.
+type CacheRule struct {
+	Path         string `yaml:"path"`
+	CacheControl string `yaml:"cache_control"`
 }
 
 // SEO contains all metadata for rendering a single page.
@@ -83,18 +230,30 @@ type SEO struct {
 	ArticleSection       string
 	ArticleTags          []string
 
+	// ProfileAuthor is the ID of the author an author page profiles.
+	ProfileAuthor string
+
 	// Navigation
 	Breadcrumbs []Breadcrumb
 
+	// Translations of this page, rendered as hreflang links
+	Alternates []Alternate
+
 	// Page type flags (for conditional rendering in templates)
-	IsHomePage  bool
-	IsArticle   bool
-	IsBlogIndex bool
+	IsHomePage   bool
+	IsArticle    bool
+	IsBlogIndex  bool
+	IsAuthorPage bool
 }
 
 // LoadSiteConfig reads site configuration from a YAML file.
 func LoadSiteConfig(path string) (SiteConfig, error) {
-	data, err := os.ReadFile(path)
+	return LoadSiteConfigFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// LoadSiteConfigFS reads site configuration from a YAML file in fsys.
+func LoadSiteConfigFS(fsys fs.FS, name string) (SiteConfig, error) {
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return SiteConfig{}, fmt.Errorf("reading site config: %w", err)
 	}
@@ -102,5 +261,13 @@ func LoadSiteConfig(path string) (SiteConfig, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return SiteConfig{}, fmt.Errorf("parsing site config: %w", err)
 	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return SiteConfig{}, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+	cfg.Location = loc
+	if err := validateFonts(cfg); err != nil {
+		return SiteConfig{}, err
+	}
 	return cfg, nil
 }
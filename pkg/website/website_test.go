@@ -57,3 +57,26 @@ name: My Site
 		t.Error("LoadSiteConfig() expected error for invalid YAML, got nil")
 	}
 }
+
+func TestLoadSiteConfig_Timezone(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "site.yaml")
+
+	if err := os.WriteFile(configFile, []byte("name: Site\ntimezone: Europe/Warsaw\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadSiteConfig(configFile)
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	if cfg.Location == nil || cfg.Location.String() != "Europe/Warsaw" {
+		t.Errorf("cfg.Location = %v, want Europe/Warsaw", cfg.Location)
+	}
+
+	if err := os.WriteFile(configFile, []byte("name: Site\ntimezone: Mars/Olympus\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSiteConfig(configFile); err == nil {
+		t.Error("LoadSiteConfig() expected error for unknown timezone, got nil")
+	}
+}